@@ -0,0 +1,75 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// The kinds of ATNDeserializationError.
+const (
+	ATNDeserializationErrorInvalidData    = 0
+	ATNDeserializationErrorTruncated      = 1
+	ATNDeserializationErrorVersion        = 2
+	ATNDeserializationErrorUUID           = 3
+	ATNDeserializationErrorStateType      = 4
+	ATNDeserializationErrorTransitionType = 5
+	ATNDeserializationErrorLexerAction    = 6
+)
+
+// ATNDeserializationError reports serialized ATN data that could not be
+// decoded. Position is the index of the offending value in the data. For
+// SerializedVersionNoUUID data stored in 16-bit words it counts decoded values
+// rather than words.
+type ATNDeserializationError struct {
+	Kind     int
+	Position int
+	Message  string
+}
+
+func NewATNDeserializationError(kind, position int, message string) *ATNDeserializationError {
+	return &ATNDeserializationError{Kind: kind, Position: position, Message: message}
+}
+
+func (e *ATNDeserializationError) Error() string {
+	return "ATN deserialization error at position " + strconv.Itoa(e.Position) + ": " + e.Message
+}
+
+// ATNVerificationViolation describes a state that breaks one of the structural
+// assumptions checked after an ATN has been read. Position is the index in the
+// data at which the state was defined, or -1 if the state was not read from
+// the data.
+type ATNVerificationViolation struct {
+	StateNumber int
+	StateType   int
+	Position    int
+	Message     string
+}
+
+func (v *ATNVerificationViolation) String() string {
+	stateType := "INVALID"
+
+	if v.StateType >= 0 && v.StateType < len(ATNStateSerializationNames) {
+		stateType = ATNStateSerializationNames[v.StateType]
+	}
+
+	return "state " + strconv.Itoa(v.StateNumber) + " (" + stateType + ", position " + strconv.Itoa(v.Position) + "): " + v.Message
+}
+
+// ATNVerificationError reports every violation found while verifying an ATN.
+type ATNVerificationError struct {
+	Violations []*ATNVerificationViolation
+}
+
+func (e *ATNVerificationError) Error() string {
+	messages := make([]string, len(e.Violations))
+
+	for i, v := range e.Violations {
+		messages[i] = v.String()
+	}
+
+	return "ATN verification failed: " + strings.Join(messages, "; ")
+}
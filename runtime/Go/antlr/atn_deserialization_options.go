@@ -12,14 +12,48 @@ type ATNDeserializationOptions struct {
 	generateRuleBypassTransitions bool
 }
 
+// NewATNDeserializationOptions returns options that may be changed, copied
+// from CopyFrom if it is not nil. The copy of read-only options, such as
+// ATNDeserializationOptionsdefaultOptions, is not read-only.
 func NewATNDeserializationOptions(CopyFrom *ATNDeserializationOptions) *ATNDeserializationOptions {
 	o := new(ATNDeserializationOptions)
 
 	if CopyFrom != nil {
-		o.readOnly = CopyFrom.readOnly
 		o.verifyATN = CopyFrom.verifyATN
 		o.generateRuleBypassTransitions = CopyFrom.generateRuleBypassTransitions
 	}
 
 	return o
 }
+
+// SetVerifyATN sets whether the deserializer checks the structural assumptions
+// the simulators make about the ATN after reading it. It panics if the options
+// are read-only.
+func (o *ATNDeserializationOptions) SetVerifyATN(verifyATN bool) {
+	o.throwIfReadOnly()
+	o.verifyATN = verifyATN
+}
+
+func (o *ATNDeserializationOptions) IsVerifyATN() bool {
+	return o.verifyATN
+}
+
+// SetGenerateRuleBypassTransitions sets whether the deserializer adds the rule
+// bypass transitions used to match parse tree patterns. It panics if the
+// options are read-only.
+func (o *ATNDeserializationOptions) SetGenerateRuleBypassTransitions(generate bool) {
+	o.throwIfReadOnly()
+	o.generateRuleBypassTransitions = generate
+}
+
+func (o *ATNDeserializationOptions) IsGenerateRuleBypassTransitions() bool {
+	return o.generateRuleBypassTransitions
+}
+
+// throwIfReadOnly panics if the options are read-only, such as the shared
+// ATNDeserializationOptionsdefaultOptions.
+func (o *ATNDeserializationOptions) throwIfReadOnly() {
+	if o.readOnly {
+		panic("The object is read only.")
+	}
+}
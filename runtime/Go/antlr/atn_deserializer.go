@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"unicode/utf16"
 )
 
 // This is the earliest supported serialized UUID.
@@ -23,6 +22,11 @@ var SupportedUUIDs = []string{BaseSerializedUUID, AddedUnicodeSMP}
 
 var SerializedVersion = 3
 
+// SerializedVersionNoUUID is the version of the serialization format written
+// by ANTLR 4.10 and later. It has no UUID and no value shift, stores every set
+// with full-width elements and supports all features of the UUID-based format.
+var SerializedVersionNoUUID = 4
+
 // This is the current serialized UUID.
 var SerializedUUID = AddedUnicodeSMP
 
@@ -40,7 +44,10 @@ type ATNDeserializer struct {
 	deserializationOptions *ATNDeserializationOptions
 	data                   []rune
 	pos                    int
+	version                int
 	uuid                   string
+	statePositions         []int
+	violations             []*ATNVerificationViolation
 }
 
 func NewATNDeserializer(options *ATNDeserializationOptions) *ATNDeserializer {
@@ -81,10 +88,89 @@ func (a *ATNDeserializer) isFeatureSupported(feature, actualUUID string) bool {
 	return idx2 >= idx1
 }
 
+// DeserializeFromUInt16 deserializes an ATN from 16-bit words. The format is
+// selected from the version header: SerializedVersion data is shifted by 2
+// and carries a UUID, while SerializedVersionNoUUID data stores values above
+// 0x7FFF in two words.
 func (a *ATNDeserializer) DeserializeFromUInt16(data []uint16) *ATN {
-	a.reset(utf16.Decode(data))
+	a.reset(data)
+
+	return a.deserialize()
+}
+
+// DeserializeFromInt32 deserializes an ATN from 32-bit values, the form in
+// which the Go target of ANTLR 4.10 and later embeds SerializedVersionNoUUID
+// data. Data in the older SerializedVersion format is accepted too.
+func (a *ATNDeserializer) DeserializeFromInt32(data []int32) *ATN {
+	if len(data) > 0 && int(data[0]) == SerializedVersion {
+		words := make([]uint16, len(data))
+
+		for i, v := range data {
+			words[i] = uint16(v)
+		}
+
+		return a.DeserializeFromUInt16(words)
+	}
+
+	a.data = data
+	a.pos = 0
+
+	return a.deserialize()
+}
+
+// Deserialize is like DeserializeFromUInt16 but returns an error instead of
+// panicking when the data cannot be decoded. The error is an
+// *ATNDeserializationError, or an *ATNVerificationError listing every
+// violation if the options enable verification and the ATN fails it.
+func (a *ATNDeserializer) Deserialize(data []uint16) (atn *ATN, err error) {
+	defer a.recoverError(&err)
+
+	return a.DeserializeFromUInt16(data), nil
+}
+
+// DeserializeInt32 is like DeserializeFromInt32 but returns an error instead
+// of panicking, in the same way as Deserialize.
+func (a *ATNDeserializer) DeserializeInt32(data []int32) (atn *ATN, err error) {
+	defer a.recoverError(&err)
+
+	return a.DeserializeFromInt32(data), nil
+}
+
+// recoverError turns a panic raised while deserializing into an error. Panics
+// that are not already deserialization or verification errors, such as an
+// out of range state number, are attributed to the last value read.
+func (a *ATNDeserializer) recoverError(err *error) {
+	r := recover()
+
+	if r == nil {
+		return
+	}
+
+	switch e := r.(type) {
+	case *ATNDeserializationError:
+		*err = e
+
+	case *ATNVerificationError:
+		*err = e
+
+	default:
+		position := a.pos - 1
+
+		if position < 0 {
+			position = 0
+		}
+
+		*err = NewATNDeserializationError(ATNDeserializationErrorInvalidData, position, fmt.Sprint(r))
+	}
+}
+
+func (a *ATNDeserializer) deserialize() *ATN {
+	a.statePositions = a.statePositions[:0]
 	a.checkVersion()
-	a.checkUUID()
+
+	if a.isLegacyFormat() {
+		a.checkUUID()
+	}
 
 	atn := a.readATN()
 
@@ -94,12 +180,17 @@ func (a *ATNDeserializer) DeserializeFromUInt16(data []uint16) *ATN {
 
 	sets := make([]*IntervalSet, 0)
 
-	// First, deserialize sets with 16-bit arguments <= U+FFFF.
-	sets = a.readSets(atn, sets, a.readInt)
-	// Next, if the ATN was serialized with the Unicode SMP feature,
-	// deserialize sets with 32-bit arguments <= U+10FFFF.
-	if (a.isFeatureSupported(AddedUnicodeSMP, a.uuid)) {
-		sets = a.readSets(atn, sets, a.readInt32)
+	if a.isLegacyFormat() {
+		// First, deserialize sets with 16-bit arguments <= U+FFFF.
+		sets = a.readSets(atn, sets, a.readInt)
+		// Next, if the ATN was serialized with the Unicode SMP feature,
+		// deserialize sets with 32-bit arguments <= U+10FFFF.
+		if (a.isFeatureSupported(AddedUnicodeSMP, a.uuid)) {
+			sets = a.readSets(atn, sets, a.readInt32)
+		}
+	} else {
+		// All sets are stored in a single section with full-width arguments.
+		sets = a.readSets(atn, sets, a.readInt)
 	}
 
 	a.readEdges(atn, sets)
@@ -118,15 +209,24 @@ func (a *ATNDeserializer) DeserializeFromUInt16(data []uint16) *ATN {
 
 }
 
-func (a *ATNDeserializer) reset(data []rune) {
+func (a *ATNDeserializer) reset(data []uint16) {
+	if len(data) > 0 && int(data[0]) == SerializedVersionNoUUID {
+		a.data = decodeIntsEncodedAs16BitWords(data)
+		a.pos = 0
+
+		return
+	}
+
+	// Each value is a separate 16-bit word; decoding the data as UTF-16 would
+	// merge values in the surrogate range into a single rune.
 	temp := make([]rune, len(data))
 
 	for i, c := range data {
 		// Don't adjust the first value since that's the version number
 		if i == 0 {
-			temp[i] = c
+			temp[i] = rune(c)
 		} else {
-			temp[i] = c - 2
+			temp[i] = rune(c) - 2
 		}
 	}
 
@@ -134,19 +234,61 @@ func (a *ATNDeserializer) reset(data []rune) {
 	a.pos = 0
 }
 
+// decodeIntsEncodedAs16BitWords expands SerializedVersionNoUUID data stored in
+// 16-bit words. Values up to 0x7FFF take one word. Larger values take two, the
+// first of which has its high bit set, and -1 is written as 0xFFFF 0xFFFF.
+func decodeIntsEncodedAs16BitWords(data []uint16) []rune {
+	result := make([]rune, 0, len(data))
+
+	for i := 0; i < len(data); i++ {
+		v := data[i]
+
+		if v&0x8000 == 0 {
+			result = append(result, rune(v))
+
+			continue
+		}
+
+		i++
+
+		if i >= len(data) {
+			panic(NewATNDeserializationError(ATNDeserializationErrorTruncated, len(result), "expected a second 16-bit word"))
+		}
+
+		next := data[i]
+
+		if v == 0xFFFF && next == 0xFFFF {
+			result = append(result, -1)
+		} else {
+			result = append(result, rune(v&0x7FFF)<<16|rune(next))
+		}
+	}
+
+	return result
+}
+
+// isLegacyFormat reports whether the ATN being deserialized uses the
+// UUID-based SerializedVersion format.
+func (a *ATNDeserializer) isLegacyFormat() bool {
+	return a.version == SerializedVersion
+}
+
 func (a *ATNDeserializer) checkVersion() {
 	version := a.readInt()
 
-	if version != SerializedVersion {
-		panic("Could not deserialize ATN with version " + strconv.Itoa(version) + " (expected " + strconv.Itoa(SerializedVersion) + ").")
+	if version != SerializedVersion && version != SerializedVersionNoUUID {
+		panic(NewATNDeserializationError(ATNDeserializationErrorVersion, a.pos-1, "Could not deserialize ATN with version "+strconv.Itoa(version)+" (expected "+strconv.Itoa(SerializedVersion)+" or "+strconv.Itoa(SerializedVersionNoUUID)+")."))
 	}
+
+	a.version = version
 }
 
 func (a *ATNDeserializer) checkUUID() {
+	position := a.pos
 	uuid := a.readUUID()
 
 	if stringInSlice(uuid, SupportedUUIDs) < 0 {
-		panic("Could not deserialize ATN with UUID: " + uuid + " (expected " + SerializedUUID + " or a legacy UUID).")
+		panic(NewATNDeserializationError(ATNDeserializationErrorUUID, position, "Could not deserialize ATN with UUID: "+uuid+" (expected "+SerializedUUID+" or a legacy UUID)."))
 	}
 
 	a.uuid = uuid
@@ -166,6 +308,8 @@ func (a *ATNDeserializer) readStates(atn *ATN) {
 	nstates := a.readInt()
 
 	for i := 0; i < nstates; i++ {
+		a.statePositions = append(a.statePositions, a.pos)
+
 		stype := a.readInt()
 
 		// Ignore bad types of states
@@ -177,7 +321,7 @@ func (a *ATNDeserializer) readStates(atn *ATN) {
 
 		ruleIndex := a.readInt()
 
-		if ruleIndex == 0xFFFF {
+		if a.isLegacyFormat() && ruleIndex == 0xFFFF {
 			ruleIndex = -1
 		}
 
@@ -245,7 +389,7 @@ func (a *ATNDeserializer) readRules(atn *ATN) {
 		if atn.grammarType == ATNTypeLexer {
 			tokenType := a.readInt()
 
-			if tokenType == 0xFFFF {
+			if a.isLegacyFormat() && tokenType == 0xFFFF {
 				tokenType = TokenEOF
 			}
 
@@ -347,18 +491,18 @@ func (a *ATNDeserializer) readEdges(atn *ATN, sets []*IntervalSet) {
 	for i := 0; i < len(atn.states); i++ {
 		state := atn.states[i]
 
-		if s2, ok := state.(*BaseBlockStartState); ok {
+		if s2, ok := state.(BlockStartState); ok {
 			// We need to know the end state to set its start state
-			if s2.endState == nil {
-				panic("IllegalState")
+			if s2.getEndState() == nil {
+				panic(a.newStateError(state, "block start state has no end state"))
 			}
 
 			// Block end states can only be associated to a single block start state
-			if s2.endState.startState != nil {
-				panic("IllegalState")
+			if s2.getEndState().startState != nil {
+				panic(a.newStateError(state, "block end state is shared by several block start states"))
 			}
 
-			s2.endState.startState = state
+			s2.getEndState().startState = state
 		}
 
 		if s2, ok := state.(*PlusLoopbackState); ok {
@@ -403,13 +547,13 @@ func (a *ATNDeserializer) readLexerActions(atn *ATN) {
 			actionType := a.readInt()
 			data1 := a.readInt()
 
-			if data1 == 0xFFFF {
+			if a.isLegacyFormat() && data1 == 0xFFFF {
 				data1 = -1
 			}
 
 			data2 := a.readInt()
 
-			if data2 == 0xFFFF {
+			if a.isLegacyFormat() && data2 == 0xFFFF {
 				data2 = -1
 			}
 
@@ -423,6 +567,8 @@ func (a *ATNDeserializer) readLexerActions(atn *ATN) {
 func (a *ATNDeserializer) generateRuleBypassTransitions(atn *ATN) {
 	count := len(atn.ruleToStartState)
 
+	atn.ruleToTokenType = make([]int, count)
+
 	for i := 0; i < count; i++ {
 		atn.ruleToTokenType[i] = atn.maxTokenType + i + 1
 	}
@@ -445,7 +591,7 @@ func (a *ATNDeserializer) generateRuleBypassTransition(atn *ATN, idx int) {
 
 	bypassStart.endState = bypassStop
 
-	atn.defineDecisionState(bypassStart.BaseDecisionState)
+	atn.defineDecisionState(bypassStart)
 
 	bypassStop.startState = bypassStart
 
@@ -468,7 +614,7 @@ func (a *ATNDeserializer) generateRuleBypassTransition(atn *ATN, idx int) {
 		}
 
 		if excludeTransition == nil {
-			panic("Couldn't identify final state of the precedence rule prefix section.")
+			panic(a.newStateError(atn.ruleToStartState[idx], "Couldn't identify final state of the precedence rule prefix section."))
 		}
 	} else {
 		endState = atn.ruleToStopState[idx]
@@ -494,11 +640,10 @@ func (a *ATNDeserializer) generateRuleBypassTransition(atn *ATN, idx int) {
 
 	// All transitions leaving the rule start state need to leave blockStart instead
 	ruleToStartState := atn.ruleToStartState[idx]
-	count := len(ruleToStartState.GetTransitions())
 
-	for count > 0 {
+	for count := len(ruleToStartState.GetTransitions()); count > 0; count-- {
 		bypassStart.AddTransition(ruleToStartState.GetTransitions()[count-1], -1)
-		ruleToStartState.SetTransitions([]Transition{ruleToStartState.GetTransitions()[len(ruleToStartState.GetTransitions())-1]})
+		ruleToStartState.SetTransitions(ruleToStartState.GetTransitions()[:count-1])
 	}
 
 	// Link the new states
@@ -562,11 +707,16 @@ func (a *ATNDeserializer) markPrecedenceDecisions(atn *ATN) {
 	}
 }
 
+// verifyATN checks the structural assumptions the simulators make about atn.
+// All violations are collected and reported together in an
+// ATNVerificationError.
 func (a *ATNDeserializer) verifyATN(atn *ATN) {
 	if !a.deserializationOptions.verifyATN {
 		return
 	}
 
+	a.violations = nil
+
 	// Verify assumptions
 	for i := 0; i < len(atn.states); i++ {
 		state := atn.states[i]
@@ -575,74 +725,109 @@ func (a *ATNDeserializer) verifyATN(atn *ATN) {
 			continue
 		}
 
-		a.checkCondition(state.GetEpsilonOnlyTransitions() || len(state.GetTransitions()) <= 1, "")
+		a.checkCondition(state, state.GetEpsilonOnlyTransitions() || len(state.GetTransitions()) <= 1, "mixes epsilon and non-epsilon transitions")
 
-		switch s2 := state.(type) {
-		case *PlusBlockStartState:
-			a.checkCondition(s2.loopBackState != nil, "")
+		if s2, ok := state.(*PlusBlockStartState); ok {
+			a.checkCondition(state, s2.loopBackState != nil, "plus block start state has no loop back state")
+		}
 
-		case *StarLoopEntryState:
-			a.checkCondition(s2.loopBackState != nil, "")
-			a.checkCondition(len(s2.GetTransitions()) == 2, "")
+		if s2, ok := state.(*StarLoopEntryState); ok {
+			a.checkCondition(state, s2.loopBackState != nil, "star loop entry state has no loop back state")
 
-			switch s2 := state.(type) {
-			case *StarBlockStartState:
-				var _, ok2 = s2.GetTransitions()[1].getTarget().(*LoopEndState)
+			if a.checkCondition(state, len(s2.GetTransitions()) == 2, "star loop entry state does not have 2 transitions") {
+				switch s2.GetTransitions()[0].getTarget().(type) {
+				case *StarBlockStartState:
+					_, ok2 := s2.GetTransitions()[1].getTarget().(*LoopEndState)
 
-				a.checkCondition(ok2, "")
-				a.checkCondition(!s2.nonGreedy, "")
+					a.checkCondition(state, ok2, "star loop entry state does not exit to a loop end state")
+					a.checkCondition(state, !s2.nonGreedy, "star loop entry state entering the block first is non-greedy")
 
-			case *LoopEndState:
-				var s3, ok2 = s2.GetTransitions()[1].getTarget().(*StarBlockStartState)
+				case *LoopEndState:
+					_, ok2 := s2.GetTransitions()[1].getTarget().(*StarBlockStartState)
 
-				a.checkCondition(ok2, "")
-				a.checkCondition(s3.nonGreedy, "")
+					a.checkCondition(state, ok2, "star loop entry state does not enter a star block start state")
+					a.checkCondition(state, s2.nonGreedy, "star loop entry state exiting first is greedy")
 
-			default:
-				panic("IllegalState")
+				default:
+					a.checkCondition(state, false, "star loop entry state targets neither a star block start state nor a loop end state")
+				}
 			}
+		}
 
-		case *StarLoopbackState:
-			a.checkCondition(len(state.GetTransitions()) == 1, "")
-
-			var _, ok2 = state.GetTransitions()[0].getTarget().(*StarLoopEntryState)
-
-			a.checkCondition(ok2, "")
+		if _, ok := state.(*StarLoopbackState); ok {
+			if a.checkCondition(state, len(state.GetTransitions()) == 1, "star loop back state does not have 1 transition") {
+				_, ok2 := state.GetTransitions()[0].getTarget().(*StarLoopEntryState)
 
-		case *LoopEndState:
-			a.checkCondition(s2.loopBackState != nil, "")
+				a.checkCondition(state, ok2, "star loop back state does not target a star loop entry state")
+			}
+		}
 
-		case *RuleStartState:
-			a.checkCondition(s2.stopState != nil, "")
+		if s2, ok := state.(*LoopEndState); ok {
+			a.checkCondition(state, s2.loopBackState != nil, "loop end state has no loop back state")
+		}
 
-		case *BaseBlockStartState:
-			a.checkCondition(s2.endState != nil, "")
+		if s2, ok := state.(*RuleStartState); ok {
+			a.checkCondition(state, s2.stopState != nil, "rule start state has no stop state")
+		}
 
-		case *BlockEndState:
-			a.checkCondition(s2.startState != nil, "")
+		if s2, ok := state.(BlockStartState); ok {
+			a.checkCondition(state, s2.getEndState() != nil, "block start state has no end state")
+		}
 
-		case DecisionState:
-			a.checkCondition(len(s2.GetTransitions()) <= 1 || s2.getDecision() >= 0, "")
+		if s2, ok := state.(*BlockEndState); ok {
+			a.checkCondition(state, s2.startState != nil, "block end state has no start state")
+		}
 
-		default:
-			var _, ok = s2.(*RuleStopState)
+		if s2, ok := state.(DecisionState); ok {
+			a.checkCondition(state, len(s2.GetTransitions()) <= 1 || s2.getDecision() >= 0, "decision state with several transitions has no decision number")
+		} else {
+			_, ok := state.(*RuleStopState)
 
-			a.checkCondition(len(s2.GetTransitions()) <= 1 || ok, "")
+			a.checkCondition(state, len(state.GetTransitions()) <= 1 || ok, "non-decision state has several transitions")
 		}
 	}
+
+	if len(a.violations) > 0 {
+		panic(&ATNVerificationError{Violations: a.violations})
+	}
 }
 
-func (a *ATNDeserializer) checkCondition(condition bool, message string) {
+// checkCondition records a violation by state if condition does not hold, and
+// returns condition.
+func (a *ATNDeserializer) checkCondition(state ATNState, condition bool, message string) bool {
 	if !condition {
-		if message == "" {
-			message = "IllegalState"
-		}
+		a.violations = append(a.violations, &ATNVerificationViolation{
+			StateNumber: state.GetStateNumber(),
+			StateType:   state.GetStateType(),
+			Position:    a.statePosition(state),
+			Message:     message,
+		})
+	}
+
+	return condition
+}
 
-		panic(message)
+// statePosition returns the index in the data at which state was defined, or
+// -1 if it was added after reading.
+func (a *ATNDeserializer) statePosition(state ATNState) int {
+	n := state.GetStateNumber()
+
+	if n >= 0 && n < len(a.statePositions) {
+		return a.statePositions[n]
 	}
+
+	return -1
+}
+
+func (a *ATNDeserializer) newStateError(state ATNState, message string) *ATNDeserializationError {
+	return NewATNDeserializationError(ATNDeserializationErrorInvalidData, a.statePosition(state), "state "+strconv.Itoa(state.GetStateNumber())+": "+message)
 }
 
 func (a *ATNDeserializer) readInt() int {
+	if a.pos >= len(a.data) {
+		panic(NewATNDeserializationError(ATNDeserializationErrorTruncated, a.pos, "unexpected end of data"))
+	}
+
 	v := a.data[a.pos]
 
 	a.pos++
@@ -651,8 +836,8 @@ func (a *ATNDeserializer) readInt() int {
 }
 
 func (a *ATNDeserializer) readInt32() int {
-	var low = a.readInt()
-	var high = a.readInt()
+	var low = a.readInt() & 0xFFFF
+	var high = a.readInt() & 0xFFFF
 	return low | (high << 16)
 }
 
@@ -739,7 +924,8 @@ func (a *ATNDeserializer) edgeFactory(atn *ATN, typeIndex, src, trg, arg1, arg2,
 		return NewWildcardTransition(target)
 	}
 
-	panic("The specified transition type is not valid.")
+	// The transition type is the third of the six values of an edge
+	panic(NewATNDeserializationError(ATNDeserializationErrorTransitionType, a.pos-4, "The specified transition type is not valid."))
 }
 
 func (a *ATNDeserializer) stateFactory(typeIndex, ruleIndex int) ATNState {
@@ -786,7 +972,8 @@ func (a *ATNDeserializer) stateFactory(typeIndex, ruleIndex int) ATNState {
 		s = NewLoopEndState()
 
 	default:
-		panic(fmt.Sprintf("state type %d is invalid", typeIndex))
+		// The state type is followed by the rule index
+		panic(NewATNDeserializationError(ATNDeserializationErrorStateType, a.pos-2, fmt.Sprintf("state type %d is invalid", typeIndex)))
 	}
 
 	s.SetRuleIndex(ruleIndex)
@@ -821,6 +1008,7 @@ func (a *ATNDeserializer) lexerActionFactory(typeIndex, data1, data2 int) LexerA
 		return NewLexerTypeAction(data1)
 
 	default:
-		panic(fmt.Sprintf("lexer action %d is invalid", typeIndex))
+		// The action type is followed by its two data values
+		panic(NewATNDeserializationError(ATNDeserializationErrorLexerAction, a.pos-3, fmt.Sprintf("lexer action %d is invalid", typeIndex)))
 	}
 }
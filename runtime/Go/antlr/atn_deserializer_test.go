@@ -0,0 +1,281 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestATNDeserializationOptionsReadOnly(t *testing.T) {
+	for name, set := range map[string]func(o *ATNDeserializationOptions){
+		"SetVerifyATN":                     func(o *ATNDeserializationOptions) { o.SetVerifyATN(true) },
+		"SetGenerateRuleBypassTransitions": func(o *ATNDeserializationOptions) { o.SetGenerateRuleBypassTransitions(true) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: no panic on the default options", name)
+				}
+			}()
+
+			set(ATNDeserializationOptionsdefaultOptions)
+		}()
+
+		// A copy of the default options may be changed
+		set(NewATNDeserializationOptions(ATNDeserializationOptionsdefaultOptions))
+	}
+
+	if ATNDeserializationOptionsdefaultOptions.IsVerifyATN() || ATNDeserializationOptionsdefaultOptions.IsGenerateRuleBypassTransitions() {
+		t.Error("default options changed")
+	}
+}
+
+func TestATNDeserializerDeserialize(t *testing.T) {
+	atn, err := NewATNDeserializer(nil).Deserialize(calcSerializedParserATN)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compareATNs(t, "Deserialize", calcParserATN, atn)
+
+	atn, err = NewATNDeserializer(nil).DeserializeInt32(NewATNSerializer(calcParserATN).SerializeInt32())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compareATNs(t, "DeserializeInt32", calcParserATN, atn)
+}
+
+// deserializationError returns err as an *ATNDeserializationError, failing t
+// if it is not one.
+func deserializationError(t *testing.T, name string, err error) *ATNDeserializationError {
+	e, ok := err.(*ATNDeserializationError)
+
+	if !ok {
+		t.Fatalf("%s: got error %v of type %T, want an *ATNDeserializationError", name, err, err)
+	}
+
+	return e
+}
+
+func TestATNDeserializerTruncated(t *testing.T) {
+	words := calcSerializedParserATN
+
+	for n := 0; n < len(words); n++ {
+		_, err := NewATNDeserializer(nil).Deserialize(words[:n])
+
+		deserializationError(t, "v3", err)
+	}
+
+	// Missing the last value, the count of lexer actions
+	_, err := NewATNDeserializer(nil).Deserialize(words[:len(words)-1])
+
+	if e := deserializationError(t, "v3 end", err); e.Kind != ATNDeserializationErrorTruncated || e.Position != len(words)-1 {
+		t.Errorf("got kind %d at %d, want %d at %d", e.Kind, e.Position, ATNDeserializationErrorTruncated, len(words)-1)
+	}
+
+	words32 := NewATNSerializer(calcParserATN).SerializeInt32()
+
+	for n := 0; n < len(words32); n++ {
+		_, err := NewATNDeserializer(nil).DeserializeInt32(words32[:n])
+
+		deserializationError(t, "v4", err)
+	}
+}
+
+func TestATNDeserializerBadVersionAndUUID(t *testing.T) {
+	words := append([]uint16(nil), calcSerializedParserATN...)
+	words[0] = 5
+
+	_, err := NewATNDeserializer(nil).Deserialize(words)
+
+	if e := deserializationError(t, "version", err); e.Kind != ATNDeserializationErrorVersion || e.Position != 0 {
+		t.Errorf("got kind %d at %d, want %d at 0", e.Kind, e.Position, ATNDeserializationErrorVersion)
+	}
+
+	_, err = NewATNDeserializer(nil).DeserializeInt32([]int32{5})
+
+	if e := deserializationError(t, "int32 version", err); e.Kind != ATNDeserializationErrorVersion {
+		t.Errorf("got kind %d, want %d", e.Kind, ATNDeserializationErrorVersion)
+	}
+
+	words = append([]uint16(nil), calcSerializedParserATN...)
+	words[1]++
+
+	_, err = NewATNDeserializer(nil).Deserialize(words)
+
+	if e := deserializationError(t, "UUID", err); e.Kind != ATNDeserializationErrorUUID || e.Position != 1 {
+		t.Errorf("got kind %d at %d, want %d at 1", e.Kind, e.Position, ATNDeserializationErrorUUID)
+	}
+}
+
+func TestATNDeserializerRecoversRuntimePanics(t *testing.T) {
+	words := NewATNSerializer(calcParserATN).SerializeInt32()
+
+	// Find the start state of the first rule, just after the rule count
+	d := NewATNDeserializer(nil)
+	d.data = words
+	d.checkVersion()
+	d.readStates(d.readATN())
+
+	position := d.pos + 1
+	words[position] = 10000
+
+	_, err := NewATNDeserializer(nil).DeserializeInt32(words)
+	e := deserializationError(t, "rule start state", err)
+
+	if e.Kind != ATNDeserializationErrorInvalidData || e.Position != position || !strings.Contains(e.Message, "index out of range") {
+		t.Errorf("got kind %d at %d: %s, want %d at %d", e.Kind, e.Position, e.Message, ATNDeserializationErrorInvalidData, position)
+	}
+}
+
+func TestATNDeserializerVerification(t *testing.T) {
+	atn := NewATNDeserializer(nil).DeserializeFromUInt16(calcSerializedParserATN)
+
+	// The loop entry of block : '{' stat* '}' loses its exit
+	entry := atn.states[64]
+
+	if entry.GetStateType() != ATNStateStarLoopEntry {
+		t.Fatalf("state 64 is of type %d", entry.GetStateType())
+	}
+
+	entry.SetTransitions(entry.GetTransitions()[:1])
+
+	words := NewATNSerializer(atn).Serialize()
+
+	if _, err := NewATNDeserializer(nil).Deserialize(words); err != nil {
+		t.Fatalf("got error %v without verification", err)
+	}
+
+	options := NewATNDeserializationOptions(nil)
+
+	options.SetVerifyATN(true)
+
+	d := NewATNDeserializer(options)
+	_, err := d.Deserialize(words)
+	e, ok := err.(*ATNVerificationError)
+
+	if !ok {
+		t.Fatalf("got error %v of type %T, want an *ATNVerificationError", err, err)
+	}
+
+	if len(e.Violations) != 1 {
+		t.Fatalf("got violations %v, want 1", e)
+	}
+
+	v := e.Violations[0]
+
+	if v.StateNumber != 64 || v.StateType != ATNStateStarLoopEntry || v.Position != d.statePositions[64] || v.Message != "star loop entry state does not have 2 transitions" {
+		t.Errorf("got violation %s", v)
+	}
+
+	if want := "ATN verification failed: state 64 (STAR_LOOP_ENTRY, position "; !strings.HasPrefix(e.Error(), want) {
+		t.Errorf("got %q, want it to start with %q", e.Error(), want)
+	}
+
+	// The Calc ATN itself is valid
+	if _, err := NewATNDeserializer(options).Deserialize(calcSerializedParserATN); err != nil {
+		t.Errorf("got error %v for the Calc ATN", err)
+	}
+}
+
+// encodeIntsWith16BitWords encodes SerializedVersionNoUUID data in 16-bit
+// words as the ANTLR 4.10 tool does for targets without 32-bit literals.
+func encodeIntsWith16BitWords(data []int32) []uint16 {
+	words := make([]uint16, 0, len(data))
+
+	for _, v := range data {
+		switch {
+		case v == -1:
+			words = append(words, 0xFFFF, 0xFFFF)
+
+		case v <= 0x7FFF:
+			words = append(words, uint16(v))
+
+		default:
+			words = append(words, uint16(v>>16)|0x8000, uint16(v&0xFFFF))
+		}
+	}
+
+	return words
+}
+
+func TestDecodeIntsEncodedAs16BitWords(t *testing.T) {
+	tests := []struct {
+		words []uint16
+		want  []rune
+	}{
+		{[]uint16{0, 1, 0x7FFF}, []rune{0, 1, 0x7FFF}},
+		{[]uint16{0xFFFF, 0xFFFF}, []rune{-1}},
+		{[]uint16{0x8000, 0x8000}, []rune{0x8000}},
+		{[]uint16{0x8000, 0xFFFF}, []rune{0xFFFF}},
+		{[]uint16{0x8010, 0xFFFF}, []rune{0x10FFFF}},
+		{[]uint16{0xFFFF, 0xFFFE}, []rune{0x7FFFFFFE}},
+		{[]uint16{4, 0xFFFF, 0xFFFF, 0x8001, 0xF600, 7}, []rune{4, -1, 0x1F600, 7}},
+	}
+
+	for _, test := range tests {
+		if got := decodeIntsEncodedAs16BitWords(test.words); fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("%x: got %x, want %x", test.words, got, test.want)
+		}
+	}
+
+	// A value above 0x7FFF without its second word
+	_, err := NewATNDeserializer(nil).Deserialize([]uint16{4, 1, 0x8000})
+
+	if e := deserializationError(t, "second word", err); e.Kind != ATNDeserializationErrorTruncated || e.Position != 2 {
+		t.Errorf("got kind %d at %d, want %d at 2", e.Kind, e.Position, ATNDeserializationErrorTruncated)
+	}
+}
+
+// TestATNDeserializerVersion4 reads CalcLexerUnicode.v4.atn, the Calc lexer
+// whose ID rule also matches U+1F600 to U+1F64F. The fixture was written with
+// SerializeInt32 and encodeIntsWith16BitWords, the 16-bit encoding of ANTLR
+// 4.10. The state for tokens has rule index -1, written as 0xFFFF 0xFFFF, and
+// the emoji range takes two words per bound.
+func TestATNDeserializerVersion4(t *testing.T) {
+	words := readSerializedATN(t, "CalcLexerUnicode.v4.atn")
+
+	if int(words[0]) != SerializedVersionNoUUID || words[5] != 0xFFFF || words[6] != 0xFFFF {
+		t.Fatalf("not a version 4 fixture with -1: %v", words[:8])
+	}
+
+	atn, err := NewATNDeserializer(nil).Deserialize(words)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := atn.states[0]; s.GetStateType() != ATNStateTokenStart || s.GetRuleIndex() != -1 {
+		t.Errorf("got state 0 of type %d in rule %d, want tokens start state in rule -1", s.GetStateType(), s.GetRuleIndex())
+	}
+
+	data := NewATNSerializer(atn).SerializeInt32()
+
+	compareATNs(t, "v4", atn, NewATNDeserializer(nil).DeserializeFromInt32(data))
+	compareWords(t, "v4 words", words, encodeIntsWith16BitWords(data))
+
+	// The emoji range is read back whole
+	lexer := NewCalcLexer(NewInputStream("x\U0001F600 = 1;"))
+	decisionToDFA := make([]*DFA, len(atn.DecisionToState))
+
+	for i, ds := range atn.DecisionToState {
+		decisionToDFA[i] = NewDFA(ds, i)
+	}
+
+	lexer.Interpreter = NewLexerATNSimulator(lexer, atn, decisionToDFA, NewPredictionContextCache())
+
+	stream := NewCommonTokenStream(lexer, TokenDefaultChannel)
+
+	stream.Fill()
+
+	if got, want := stream.GetAllTokens()[0].GetText(), "x\U0001F600"; got != want {
+		t.Errorf("got first token %q, want %q", got, want)
+	}
+}
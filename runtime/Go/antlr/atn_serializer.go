@@ -0,0 +1,416 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// ATNSerializer is the inverse of ATNDeserializer. It encodes an in-memory ATN
+// in either of the serialized forms read by ATNDeserializer. Together they
+// allow tools to load an ATN, transform it and write it back out.
+type ATNSerializer struct {
+	atn     *ATN
+	version int
+	data    []int
+}
+
+func NewATNSerializer(atn *ATN) *ATNSerializer {
+	return &ATNSerializer{atn: atn}
+}
+
+// Serialize encodes the ATN using SerializedVersion and SerializedUUID. Like
+// the code generated by the ANTLR tool, every value except the leading version
+// number is shifted by 2 so that the common values 0 and -1 (0xFFFF) do not
+// have to be escaped when the data is embedded in string literals.
+func (a *ATNSerializer) Serialize() []uint16 {
+	a.serialize(SerializedVersion)
+
+	result := make([]uint16, len(a.data))
+
+	for i, v := range a.data {
+		if i == 0 {
+			// Don't adjust the first value since that's the version number
+			result[i] = uint16(v)
+		} else {
+			result[i] = uint16((v + 2) & 0xFFFF)
+		}
+	}
+
+	return result
+}
+
+// SerializeInt32 encodes the ATN using SerializedVersionNoUUID, the format
+// embedded by the Go target of ANTLR 4.10 and later.
+func (a *ATNSerializer) SerializeInt32() []int32 {
+	a.serialize(SerializedVersionNoUUID)
+
+	result := make([]int32, len(a.data))
+
+	for i, v := range a.data {
+		result[i] = int32(v)
+	}
+
+	return result
+}
+
+func (a *ATNSerializer) serialize(version int) {
+	a.version = version
+	a.data = make([]int, 0)
+
+	a.addInt(version)
+
+	if version == SerializedVersion {
+		a.writeUUID(SerializedUUID)
+	}
+
+	a.addInt(a.atn.grammarType)
+	a.addInt(a.atn.maxTokenType)
+
+	a.writeStates()
+	a.writeRules()
+	a.writeModes()
+
+	sets := a.collectSets()
+
+	a.writeEdges(sets)
+	a.writeDecisions()
+	a.writeLexerActions()
+}
+
+func (a *ATNSerializer) addInt(v int) {
+	a.data = append(a.data, v)
+}
+
+func (a *ATNSerializer) addInt32(v int) {
+	a.addInt(v & 0xFFFF)
+	a.addInt((v >> 16) & 0xFFFF)
+}
+
+// writeUUID writes uuid in the order expected by ATNDeserializer.readUUID: the
+// least significant 16-bit word first.
+func (a *ATNSerializer) writeUUID(uuid string) {
+	bb, err := hex.DecodeString(strings.Replace(uuid, "-", "", -1))
+
+	if err != nil || len(bb) != 16 {
+		panic("Invalid UUID " + uuid + ".")
+	}
+
+	for i := 7; i >= 0; i-- {
+		a.addInt(int(bb[2*i])<<8 | int(bb[(2*i)+1]))
+	}
+}
+
+func (a *ATNSerializer) writeStates() {
+	nonGreedyStates := make([]int, 0)
+	precedenceStates := make([]int, 0)
+
+	a.addInt(len(a.atn.states))
+
+	for _, s := range a.atn.states {
+		if s == nil {
+			// Might be optimized away
+			a.addInt(ATNStateInvalidType)
+
+			continue
+		}
+
+		stype := s.GetStateType()
+
+		if ds, ok := s.(DecisionState); ok && ds.getNonGreedy() {
+			nonGreedyStates = append(nonGreedyStates, s.GetStateNumber())
+		}
+
+		if rs, ok := s.(*RuleStartState); ok && rs.isPrecedenceRule {
+			precedenceStates = append(precedenceStates, s.GetStateNumber())
+		}
+
+		a.addInt(stype)
+		a.addInt(s.GetRuleIndex())
+
+		if stype == ATNStateLoopEnd {
+			a.addInt(s.(*LoopEndState).loopBackState.GetStateNumber())
+		} else if s2, ok := s.(BlockStartState); ok {
+			a.addInt(s2.getEndState().GetStateNumber())
+		}
+	}
+
+	a.addInt(len(nonGreedyStates))
+
+	for _, n := range nonGreedyStates {
+		a.addInt(n)
+	}
+
+	a.addInt(len(precedenceStates))
+
+	for _, n := range precedenceStates {
+		a.addInt(n)
+	}
+}
+
+func (a *ATNSerializer) writeRules() {
+	a.addInt(len(a.atn.ruleToStartState))
+
+	for i, s := range a.atn.ruleToStartState {
+		a.addInt(s.GetStateNumber())
+
+		if a.atn.grammarType == ATNTypeLexer {
+			a.addInt(a.atn.ruleToTokenType[i])
+		}
+	}
+}
+
+func (a *ATNSerializer) writeModes() {
+	a.addInt(len(a.atn.modeToStartState))
+
+	for _, s := range a.atn.modeToStartState {
+		a.addInt(s.GetStateNumber())
+	}
+}
+
+// collectSets writes the sets referenced by set transitions and returns the
+// index of each set keyed by its string form. SerializedVersion data stores
+// sets with 16-bit elements first and then sets with elements above U+FFFF;
+// SerializedVersionNoUUID data stores them all in one section.
+func (a *ATNSerializer) collectSets() map[string]int {
+	keys := make([]string, 0)
+	byKey := make(map[string]*IntervalSet)
+
+	for _, s := range a.atn.states {
+		if s == nil {
+			continue
+		}
+
+		for _, t := range s.GetTransitions() {
+			st := t.getSerializationType()
+
+			if st != TransitionSET && st != TransitionNOTSET {
+				continue
+			}
+
+			set := t.getLabel()
+			key := set.String()
+
+			if _, ok := byKey[key]; !ok {
+				keys = append(keys, key)
+				byKey[key] = set
+			}
+		}
+	}
+
+	indices := make(map[string]int)
+
+	if a.version != SerializedVersion {
+		a.writeSets(keys, byKey, a.addInt)
+
+		for _, key := range keys {
+			indices[key] = len(indices)
+		}
+
+		return indices
+	}
+
+	bmpSets := make([]string, 0)
+	smpSets := make([]string, 0)
+
+	for _, key := range keys {
+		set := byKey[key]
+
+		if len(set.intervals) == 0 || set.intervals[len(set.intervals)-1].stop-1 <= 0xFFFF {
+			bmpSets = append(bmpSets, key)
+		} else {
+			smpSets = append(smpSets, key)
+		}
+	}
+
+	a.writeSets(bmpSets, byKey, a.addInt)
+	a.writeSets(smpSets, byKey, a.addInt32)
+
+	for _, key := range append(bmpSets, smpSets...) {
+		indices[key] = len(indices)
+	}
+
+	return indices
+}
+
+func (a *ATNSerializer) writeSets(keys []string, byKey map[string]*IntervalSet, writeUnicode func(int)) {
+	a.addInt(len(keys))
+
+	for _, key := range keys {
+		set := byKey[key]
+		containsEOF := set.contains(TokenEOF)
+
+		if containsEOF && set.intervals[0].stop-1 == TokenEOF {
+			a.addInt(len(set.intervals) - 1)
+		} else {
+			a.addInt(len(set.intervals))
+		}
+
+		if containsEOF {
+			a.addInt(1)
+		} else {
+			a.addInt(0)
+		}
+
+		for _, v := range set.intervals {
+			if v.start == TokenEOF {
+				if v.stop-1 == TokenEOF {
+					continue
+				}
+
+				writeUnicode(0)
+			} else {
+				writeUnicode(v.start)
+			}
+
+			writeUnicode(v.stop - 1)
+		}
+	}
+}
+
+func (a *ATNSerializer) writeEdges(sets map[string]int) {
+	nedges := 0
+
+	for _, s := range a.atn.states {
+		// Edges for rule stop states can be derived, so they are not serialized
+		if s == nil || s.GetStateType() == ATNStateRuleStop {
+			continue
+		}
+
+		nedges += len(s.GetTransitions())
+	}
+
+	a.addInt(nedges)
+
+	for _, s := range a.atn.states {
+		if s == nil || s.GetStateType() == ATNStateRuleStop {
+			continue
+		}
+
+		for _, t := range s.GetTransitions() {
+			var (
+				src              = s.GetStateNumber()
+				trg              = t.getTarget().GetStateNumber()
+				ttype            = t.getSerializationType()
+				arg1, arg2, arg3 int
+			)
+
+			switch tt := t.(type) {
+			case *RuleTransition:
+				trg = tt.followState.GetStateNumber()
+				arg1 = tt.getTarget().GetStateNumber()
+				arg2 = tt.ruleIndex
+				arg3 = tt.precedence
+
+			case *PrecedencePredicateTransition:
+				arg1 = tt.precedence
+
+			case *PredicateTransition:
+				arg1 = tt.ruleIndex
+				arg2 = tt.predIndex
+				arg3 = boolToInt(tt.isCtxDependent)
+
+			case *RangeTransition:
+				arg1 = tt.start
+				arg2 = tt.stop
+
+				if arg1 == TokenEOF {
+					arg1 = 0
+					arg3 = 1
+				}
+
+			case *AtomTransition:
+				arg1 = tt.label
+
+				if arg1 == TokenEOF {
+					arg1 = 0
+					arg3 = 1
+				}
+
+			case *ActionTransition:
+				arg1 = tt.ruleIndex
+				arg2 = tt.actionIndex
+				arg3 = boolToInt(tt.isCtxDependent)
+
+			case *NotSetTransition:
+				arg1 = sets[tt.intervalSet.String()]
+
+			case *SetTransition:
+				arg1 = sets[tt.intervalSet.String()]
+
+			case *EpsilonTransition, *WildcardTransition:
+				// No arguments
+
+			default:
+				panic(fmt.Sprintf("transition type %d is invalid", ttype))
+			}
+
+			a.addInt(src)
+			a.addInt(trg)
+			a.addInt(ttype)
+			a.addInt(arg1)
+			a.addInt(arg2)
+			a.addInt(arg3)
+		}
+	}
+}
+
+func (a *ATNSerializer) writeDecisions() {
+	a.addInt(len(a.atn.DecisionToState))
+
+	for _, s := range a.atn.DecisionToState {
+		a.addInt(s.GetStateNumber())
+	}
+}
+
+func (a *ATNSerializer) writeLexerActions() {
+	if a.atn.grammarType != ATNTypeLexer {
+		return
+	}
+
+	a.addInt(len(a.atn.lexerActions))
+
+	for _, action := range a.atn.lexerActions {
+		var data1, data2 int
+
+		switch la := action.(type) {
+		case *LexerChannelAction:
+			data1 = la.channel
+
+		case *LexerCustomAction:
+			data1 = la.ruleIndex
+			data2 = la.actionIndex
+
+		case *LexerModeAction:
+			data1 = la.mode
+
+		case *LexerPushModeAction:
+			data1 = la.mode
+
+		case *LexerTypeAction:
+			data1 = la.thetype
+
+		case *LexerMoreAction, *LexerPopModeAction, *LexerSkipAction:
+			// No arguments
+
+		default:
+			panic(fmt.Sprintf("lexer action %d is invalid", action.getActionType()))
+		}
+
+		a.addInt(action.getActionType())
+		a.addInt(data1)
+		a.addInt(data2)
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,259 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// readSerializedATN reads the 16-bit words of a serialized ATN from testdata.
+// CLexer.atn and CParser.atn hold the SerializedVersion form with
+// BaseSerializedUUID, as generated by the ANTLR tool for the C grammar of the
+// Python runtime tests. CalcLexerUnicode.v4.atn holds the
+// SerializedVersionNoUUID form, see TestATNDeserializerVersion4.
+func readSerializedATN(t *testing.T, name string) []uint16 {
+	data, err := ioutil.ReadFile("testdata/" + name)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := strings.Fields(string(data))
+	words := make([]uint16, len(fields))
+
+	for i, f := range fields {
+		v, err := strconv.ParseUint(f, 10, 16)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		words[i] = uint16(v)
+	}
+
+	return words
+}
+
+func newBypassDeserializer() *ATNDeserializer {
+	options := NewATNDeserializationOptions(nil)
+
+	options.SetGenerateRuleBypassTransitions(true)
+
+	return NewATNDeserializer(options)
+}
+
+func describeState(s ATNState) string {
+	if s == nil {
+		return "nil"
+	}
+
+	desc := fmt.Sprintf("%d type=%d rule=%d", s.GetStateNumber(), s.GetStateType(), s.GetRuleIndex())
+
+	if ds, ok := s.(DecisionState); ok {
+		desc += fmt.Sprintf(" decision=%d nongreedy=%t", ds.getDecision(), ds.getNonGreedy())
+	}
+
+	switch s2 := s.(type) {
+	case *RuleStartState:
+		desc += fmt.Sprintf(" stop=%d precedence=%t", s2.stopState.GetStateNumber(), s2.isPrecedenceRule)
+
+	case *BlockEndState:
+		desc += fmt.Sprintf(" start=%d", s2.startState.GetStateNumber())
+
+	case *LoopEndState:
+		desc += fmt.Sprintf(" loopback=%d", s2.loopBackState.GetStateNumber())
+
+	case *StarLoopEntryState:
+		desc += fmt.Sprintf(" loopback=%d precedencedecision=%t", s2.loopBackState.GetStateNumber(), s2.precedenceRuleDecision)
+
+	case *PlusBlockStartState:
+		desc += fmt.Sprintf(" loopback=%d", s2.loopBackState.GetStateNumber())
+	}
+
+	if bs, ok := s.(BlockStartState); ok {
+		desc += fmt.Sprintf(" end=%d", bs.getEndState().GetStateNumber())
+	}
+
+	for _, t := range s.GetTransitions() {
+		desc += " -> " + describeTransition(t)
+	}
+
+	return desc
+}
+
+func describeTransition(t Transition) string {
+	desc := fmt.Sprintf("%d:%d", t.getSerializationType(), t.getTarget().GetStateNumber())
+
+	switch tt := t.(type) {
+	case *RuleTransition:
+		desc += fmt.Sprintf(" follow=%d rule=%d precedence=%d", tt.followState.GetStateNumber(), tt.ruleIndex, tt.precedence)
+
+	case *PrecedencePredicateTransition:
+		desc += fmt.Sprintf(" precedence=%d", tt.precedence)
+
+	case *PredicateTransition:
+		desc += fmt.Sprintf(" rule=%d pred=%d ctx=%t", tt.ruleIndex, tt.predIndex, tt.isCtxDependent)
+
+	case *ActionTransition:
+		desc += fmt.Sprintf(" rule=%d action=%d ctx=%t", tt.ruleIndex, tt.actionIndex, tt.isCtxDependent)
+	}
+
+	if label := t.getLabel(); label != nil {
+		desc += " label=" + label.String()
+	}
+
+	return desc
+}
+
+func compareATNs(t *testing.T, name string, want, got *ATN) {
+	if got.grammarType != want.grammarType || got.maxTokenType != want.maxTokenType {
+		t.Errorf("%s: got type %d max token %d, want type %d max token %d", name, got.grammarType, got.maxTokenType, want.grammarType, want.maxTokenType)
+	}
+
+	if len(got.states) != len(want.states) {
+		t.Fatalf("%s: got %d states, want %d", name, len(got.states), len(want.states))
+	}
+
+	for i := range want.states {
+		if g, w := describeState(got.states[i]), describeState(want.states[i]); g != w {
+			t.Errorf("%s: state %d\ngot:  %s\nwant: %s", name, i, g, w)
+		}
+	}
+
+	if g, w := stateNumbers(got.DecisionToState), stateNumbers(want.DecisionToState); g != w {
+		t.Errorf("%s: got decisions %s, want %s", name, g, w)
+	}
+
+	if g, w := stateNumbers(got.ruleToStartState), stateNumbers(want.ruleToStartState); g != w {
+		t.Errorf("%s: got rule start states %s, want %s", name, g, w)
+	}
+
+	if g, w := stateNumbers(got.ruleToStopState), stateNumbers(want.ruleToStopState); g != w {
+		t.Errorf("%s: got rule stop states %s, want %s", name, g, w)
+	}
+
+	if g, w := stateNumbers(got.modeToStartState), stateNumbers(want.modeToStartState); g != w {
+		t.Errorf("%s: got mode start states %s, want %s", name, g, w)
+	}
+
+	if g, w := fmt.Sprint(got.ruleToTokenType), fmt.Sprint(want.ruleToTokenType); g != w {
+		t.Errorf("%s: got rule token types %s, want %s", name, g, w)
+	}
+
+	if len(got.lexerActions) != len(want.lexerActions) {
+		t.Fatalf("%s: got %d lexer actions, want %d", name, len(got.lexerActions), len(want.lexerActions))
+	}
+
+	for i := range want.lexerActions {
+		g, w := got.lexerActions[i], want.lexerActions[i]
+
+		if g.getActionType() != w.getActionType() || g.Hash() != w.Hash() {
+			t.Errorf("%s: lexer action %d: got %v, want %v", name, i, g, w)
+		}
+	}
+}
+
+// stateNumbers formats the state numbers of a slice of states such as
+// ATN.DecisionToState or ATN.ruleToStartState.
+func stateNumbers(states interface{}) string {
+	numbers := make([]string, 0)
+
+	switch ss := states.(type) {
+	case []DecisionState:
+		for _, s := range ss {
+			numbers = append(numbers, strconv.Itoa(s.GetStateNumber()))
+		}
+
+	case []*RuleStartState:
+		for _, s := range ss {
+			numbers = append(numbers, strconv.Itoa(s.GetStateNumber()))
+		}
+
+	case []*RuleStopState:
+		for _, s := range ss {
+			numbers = append(numbers, strconv.Itoa(s.GetStateNumber()))
+		}
+
+	case []*TokensStartState:
+		for _, s := range ss {
+			numbers = append(numbers, strconv.Itoa(s.GetStateNumber()))
+		}
+	}
+
+	return strings.Join(numbers, ",")
+}
+
+func compareWords(t *testing.T, name string, want, got interface{}) {
+	if g, w := fmt.Sprint(got), fmt.Sprint(want); g != w {
+		t.Errorf("%s: serialized words differ\ngot:  %.200s\nwant: %.200s", name, g, w)
+	}
+}
+
+func TestATNSerializerRoundTrip(t *testing.T) {
+	for _, name := range []string{"CLexer.atn", "CParser.atn"} {
+		original := NewATNDeserializer(nil).DeserializeFromUInt16(readSerializedATN(t, name))
+
+		words := NewATNSerializer(original).Serialize()
+		atn := NewATNDeserializer(nil).DeserializeFromUInt16(words)
+
+		compareATNs(t, name+" v3", original, atn)
+		compareWords(t, name+" v3", words, NewATNSerializer(atn).Serialize())
+
+		words32 := NewATNSerializer(original).SerializeInt32()
+		atn32 := NewATNDeserializer(nil).DeserializeFromInt32(words32)
+
+		compareATNs(t, name+" v4", original, atn32)
+		compareWords(t, name+" v4", words32, NewATNSerializer(atn32).SerializeInt32())
+	}
+}
+
+func TestATNSerializerKeepsUUIDFormat(t *testing.T) {
+	words := NewATNSerializer(NewATNDeserializer(nil).DeserializeFromUInt16(readSerializedATN(t, "CLexer.atn"))).Serialize()
+
+	if int(words[0]) != SerializedVersion {
+		t.Fatalf("got version %d, want %d", words[0], SerializedVersion)
+	}
+
+	d := NewATNDeserializer(nil)
+	d.reset(words)
+	d.checkVersion()
+	d.checkUUID()
+
+	if d.uuid != SerializedUUID {
+		t.Errorf("got UUID %s, want %s", d.uuid, SerializedUUID)
+	}
+
+	words32 := NewATNSerializer(NewATNDeserializer(nil).DeserializeFromUInt16(readSerializedATN(t, "CLexer.atn"))).SerializeInt32()
+
+	if int(words32[0]) != SerializedVersionNoUUID {
+		t.Fatalf("got version %d, want %d", words32[0], SerializedVersionNoUUID)
+	}
+}
+
+func TestATNSerializerRoundTripWithBypassTransitions(t *testing.T) {
+	original := newBypassDeserializer().DeserializeFromUInt16(readSerializedATN(t, "CParser.atn"))
+
+	// The bypass token types are derived and not serialized
+	ruleToTokenType := original.ruleToTokenType
+	original.ruleToTokenType = nil
+
+	defer func() {
+		original.ruleToTokenType = ruleToTokenType
+	}()
+
+	words := NewATNSerializer(original).Serialize()
+	atn := NewATNDeserializer(nil).DeserializeFromUInt16(words)
+
+	compareATNs(t, "bypass v3", original, atn)
+	compareWords(t, "bypass v3", words, NewATNSerializer(atn).Serialize())
+
+	atn32 := NewATNDeserializer(nil).DeserializeFromInt32(NewATNSerializer(original).SerializeInt32())
+
+	compareATNs(t, "bypass v4", original, atn32)
+}
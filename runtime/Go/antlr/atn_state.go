@@ -27,6 +27,24 @@ const (
 
 var ATNStateInitialNumTransitions = 4
 
+// ATNStateSerializationNames maps the serialized state types above to their
+// names.
+var ATNStateSerializationNames = []string{
+	"INVALID",
+	"BASIC",
+	"RULE_START",
+	"BLOCK_START",
+	"PLUS_BLOCK_START",
+	"STAR_BLOCK_START",
+	"TOKEN_START",
+	"RULE_STOP",
+	"BLOCK_END",
+	"STAR_LOOP_BACK",
+	"STAR_LOOP_ENTRY",
+	"PLUS_LOOP_BACK",
+	"LOOP_END",
+}
+
 type ATNState interface {
 	GetEpsilonOnlyTransitions() bool
 
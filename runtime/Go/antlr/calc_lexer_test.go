@@ -0,0 +1,202 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import "fmt"
+
+// CalcLexer and CalcParser recognize the test grammar below. They are laid
+// out as the ANTLR tool generates them, so that tests can run the runtime
+// over a real lexer and parser:
+//
+//	grammar Calc;
+//
+//	prog   : stat* EOF ;
+//	stat   : id=ID '=' value=expr[$id.text.length] ';'
+//	       | 'print' args+=expr[0] (',' args+=expr[0])* ';'
+//	       | block
+//	       | str ':' stat
+//	       | expr[0] ';'
+//	       ;
+//	block  : '{' stat* '}' ;
+//	expr[int p] : atom ('+' atom)* ;
+//	atom   : ID | INT | text | '(' expr[0] ')' ;
+//	text   : str+ ;
+//	str    : QUOTE STRING_TEXT? STRING_END ;
+//
+//	PRINT  : 'print' ;
+//	ASSIGN : '=' ;
+//	SEMI   : ';' ;
+//	COMMA  : ',' ;
+//	PLUS   : '+' ;
+//	LPAREN : '(' ;
+//	RPAREN : ')' ;
+//	LBRACE : '{' ;
+//	RBRACE : '}' ;
+//	COLON  : ':' ;
+//	ID      : [a-z]+ ;
+//	INT     : [0-9]+ ;
+//	QUOTE   : '"' -> pushMode(STR) ;
+//	INCLUDE : '#include' ' '+ [a-z]+ {include(name)} -> channel(HIDDEN) ;
+//	WS      : [ \t\r\n]+ -> channel(HIDDEN) ;
+//
+//	mode STR;
+//	STRING_TEXT : ~["\n]+ ;
+//	STRING_END  : '"' -> popMode ;
+var calcSerializedLexerATN = []uint16{
+	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 2, 19, 121, 8, 1,
+	8, 1, 4, 2, 9, 2, 4, 3, 9, 3, 4, 4, 9, 4, 4, 5, 9, 5, 4, 6, 9, 6, 4, 7, 9, 7,
+	4, 8, 9, 8, 4, 9, 9, 9, 4, 10, 9, 10, 4, 11, 9, 11, 4, 12, 9, 12, 4, 13, 9,
+	13, 4, 14, 9, 14, 4, 15, 9, 15, 4, 16, 9, 16, 4, 17, 9, 17, 4, 18, 9, 18, 3,
+	2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 3, 3, 3, 3, 4, 3, 4, 3, 5, 3, 5, 3, 6, 3,
+	6, 3, 7, 3, 7, 3, 8, 3, 8, 3, 9, 3, 9, 3, 10, 3, 10, 3, 11, 3, 11, 3, 12, 3,
+	12, 6, 12, 65, 10, 12, 13, 12, 14, 12, 66, 3, 13, 3, 13, 6, 13, 71, 10, 13,
+	13, 13, 14, 13, 72, 3, 14, 3, 14, 3, 14, 3, 14, 3, 15, 3, 15, 3, 15, 3, 15, 3,
+	15, 3, 15, 3, 15, 3, 15, 3, 15, 3, 15, 3, 15, 6, 15, 90, 10, 15, 13, 15, 14,
+	15, 91, 3, 15, 3, 15, 6, 15, 96, 10, 15, 13, 15, 14, 15, 97, 3, 15, 3, 15, 3,
+	15, 3, 15, 3, 16, 3, 16, 6, 16, 106, 10, 16, 13, 16, 14, 16, 107, 3, 16, 3,
+	16, 3, 17, 3, 17, 6, 17, 114, 10, 17, 13, 17, 14, 17, 115, 3, 18, 3, 18, 3,
+	18, 3, 18, 2, 2, 19, 4, 3, 6, 4, 8, 5, 10, 6, 12, 7, 14, 8, 16, 9, 18, 10, 20,
+	11, 22, 12, 24, 13, 26, 14, 28, 15, 30, 16, 32, 17, 34, 18, 36, 19, 4, 2, 3,
+	4, 5, 2, 11, 12, 15, 15, 34, 34, 4, 2, 12, 12, 36, 36, 2, 125, 38, 39, 7, 114,
+	2, 2, 39, 40, 7, 116, 2, 2, 40, 41, 7, 107, 2, 2, 41, 42, 7, 112, 2, 2, 42,
+	43, 7, 118, 2, 2, 4, 38, 3, 2, 2, 2, 43, 5, 3, 2, 2, 2, 44, 45, 7, 63, 2, 2,
+	6, 44, 3, 2, 2, 2, 45, 7, 3, 2, 2, 2, 46, 47, 7, 61, 2, 2, 8, 46, 3, 2, 2, 2,
+	47, 9, 3, 2, 2, 2, 48, 49, 7, 46, 2, 2, 10, 48, 3, 2, 2, 2, 49, 11, 3, 2, 2,
+	2, 50, 51, 7, 45, 2, 2, 12, 50, 3, 2, 2, 2, 51, 13, 3, 2, 2, 2, 52, 53, 7, 42,
+	2, 2, 14, 52, 3, 2, 2, 2, 53, 15, 3, 2, 2, 2, 54, 55, 7, 43, 2, 2, 16, 54, 3,
+	2, 2, 2, 55, 17, 3, 2, 2, 2, 56, 57, 7, 125, 2, 2, 18, 56, 3, 2, 2, 2, 57, 19,
+	3, 2, 2, 2, 58, 59, 7, 127, 2, 2, 20, 58, 3, 2, 2, 2, 59, 21, 3, 2, 2, 2, 60,
+	61, 7, 60, 2, 2, 22, 60, 3, 2, 2, 2, 61, 23, 3, 2, 2, 2, 62, 63, 4, 99, 124,
+	2, 64, 62, 3, 2, 2, 2, 63, 65, 3, 2, 2, 2, 65, 66, 3, 2, 2, 2, 66, 64, 3, 2,
+	2, 2, 66, 67, 3, 2, 2, 2, 24, 64, 3, 2, 2, 2, 67, 25, 3, 2, 2, 2, 68, 69, 4,
+	50, 59, 2, 70, 68, 3, 2, 2, 2, 69, 71, 3, 2, 2, 2, 71, 72, 3, 2, 2, 2, 72, 70,
+	3, 2, 2, 2, 72, 73, 3, 2, 2, 2, 26, 70, 3, 2, 2, 2, 73, 27, 3, 2, 2, 2, 74,
+	75, 7, 36, 2, 2, 76, 77, 8, 14, 2, 2, 75, 76, 3, 2, 2, 2, 28, 74, 3, 2, 2, 2,
+	77, 29, 3, 2, 2, 2, 78, 79, 7, 37, 2, 2, 79, 80, 7, 107, 2, 2, 80, 81, 7, 112,
+	2, 2, 81, 82, 7, 101, 2, 2, 82, 83, 7, 110, 2, 2, 83, 84, 7, 119, 2, 2, 84,
+	85, 7, 102, 2, 2, 85, 86, 7, 103, 2, 2, 87, 88, 7, 34, 2, 2, 89, 87, 3, 2, 2,
+	2, 88, 90, 3, 2, 2, 2, 90, 91, 3, 2, 2, 2, 91, 89, 3, 2, 2, 2, 91, 92, 3, 2,
+	2, 2, 93, 94, 4, 99, 124, 2, 95, 93, 3, 2, 2, 2, 94, 96, 3, 2, 2, 2, 96, 97,
+	3, 2, 2, 2, 97, 95, 3, 2, 2, 2, 97, 98, 3, 2, 2, 2, 99, 100, 8, 15, 3, 2, 101,
+	102, 8, 15, 4, 2, 86, 89, 3, 2, 2, 2, 92, 95, 3, 2, 2, 2, 98, 99, 3, 2, 2, 2,
+	100, 101, 3, 2, 2, 2, 30, 78, 3, 2, 2, 2, 102, 31, 3, 2, 2, 2, 103, 104, 9, 2,
+	2, 2, 105, 103, 3, 2, 2, 2, 104, 106, 3, 2, 2, 2, 106, 107, 3, 2, 2, 2, 107,
+	105, 3, 2, 2, 2, 107, 108, 3, 2, 2, 2, 109, 110, 8, 16, 4, 2, 108, 109, 3, 2,
+	2, 2, 32, 105, 3, 2, 2, 2, 110, 33, 3, 2, 2, 2, 111, 112, 10, 3, 2, 2, 113,
+	111, 3, 2, 2, 2, 112, 114, 3, 2, 2, 2, 114, 115, 3, 2, 2, 2, 115, 113, 3, 2,
+	2, 2, 115, 116, 3, 2, 2, 2, 34, 113, 3, 2, 2, 2, 116, 35, 3, 2, 2, 2, 117,
+	118, 7, 36, 2, 2, 119, 120, 8, 18, 5, 2, 118, 119, 3, 2, 2, 2, 36, 117, 3, 2,
+	2, 2, 120, 37, 3, 2, 2, 2, 2, 4, 3, 2, 2, 2, 2, 6, 3, 2, 2, 2, 2, 8, 3, 2, 2,
+	2, 2, 10, 3, 2, 2, 2, 2, 12, 3, 2, 2, 2, 2, 14, 3, 2, 2, 2, 2, 16, 3, 2, 2, 2,
+	2, 18, 3, 2, 2, 2, 2, 20, 3, 2, 2, 2, 2, 22, 3, 2, 2, 2, 2, 24, 3, 2, 2, 2, 2,
+	26, 3, 2, 2, 2, 2, 28, 3, 2, 2, 2, 2, 30, 3, 2, 2, 2, 2, 32, 3, 2, 2, 2, 3,
+	34, 3, 2, 2, 2, 3, 36, 3, 2, 2, 2, 16, 2, 3, 64, 66, 70, 72, 89, 91, 95, 97,
+	105, 107, 113, 115, 6, 7, 3, 2, 3, 15, 2, 2, 3, 2, 6, 2, 2,
+}
+
+var calcLexerATN = NewATNDeserializer(nil).DeserializeFromUInt16(calcSerializedLexerATN)
+
+var calcLexerModeNames = []string{
+	"DEFAULT_MODE", "STR",
+}
+
+var calcLiteralNames = []string{
+	"", "'print'", "'='", "';'", "','", "'+'", "'('", "')'", "'{'", "'}'",
+	"':'",
+}
+
+var calcSymbolicNames = []string{
+	"", "PRINT", "ASSIGN", "SEMI", "COMMA", "PLUS", "LPAREN", "RPAREN", "LBRACE",
+	"RBRACE", "COLON", "ID", "INT", "QUOTE", "INCLUDE", "WS", "STRING_TEXT",
+	"STRING_END",
+}
+
+var calcLexerRuleNames = []string{
+	"PRINT", "ASSIGN", "SEMI", "COMMA", "PLUS", "LPAREN", "RPAREN", "LBRACE",
+	"RBRACE", "COLON", "ID", "INT", "QUOTE", "INCLUDE", "WS", "STRING_TEXT",
+	"STRING_END",
+}
+
+type CalcLexer struct {
+	*BaseLexer
+	modeNames []string
+
+	// Include is called by the action of INCLUDE with the name of the
+	// included input.
+	Include func(name string)
+}
+
+func NewCalcLexer(input CharStream) *CalcLexer {
+	var lexerDecisionToDFA = make([]*DFA, len(calcLexerATN.DecisionToState))
+
+	for index, ds := range calcLexerATN.DecisionToState {
+		lexerDecisionToDFA[index] = NewDFA(ds, index)
+	}
+
+	l := new(CalcLexer)
+
+	l.BaseLexer = NewBaseLexer(input)
+	l.Interpreter = NewLexerATNSimulator(l, calcLexerATN, lexerDecisionToDFA, NewPredictionContextCache())
+
+	l.modeNames = calcLexerModeNames
+	l.RuleNames = calcLexerRuleNames
+	l.LiteralNames = calcLiteralNames
+	l.SymbolicNames = calcSymbolicNames
+	l.GrammarFileName = "Calc.g4"
+
+	return l
+}
+
+// CalcLexer tokens.
+const (
+	CalcLexerPRINT       = 1
+	CalcLexerASSIGN      = 2
+	CalcLexerSEMI        = 3
+	CalcLexerCOMMA       = 4
+	CalcLexerPLUS        = 5
+	CalcLexerLPAREN      = 6
+	CalcLexerRPAREN      = 7
+	CalcLexerLBRACE      = 8
+	CalcLexerRBRACE      = 9
+	CalcLexerCOLON       = 10
+	CalcLexerID          = 11
+	CalcLexerINT         = 12
+	CalcLexerQUOTE       = 13
+	CalcLexerINCLUDE     = 14
+	CalcLexerWS          = 15
+	CalcLexerSTRING_TEXT = 16
+	CalcLexerSTRING_END  = 17
+)
+
+// CalcLexerSTR is the CalcLexer mode.
+const CalcLexerSTR = 1
+
+func (l *CalcLexer) Action(localctx RuleContext, ruleIndex, actionIndex int) {
+	switch ruleIndex {
+	case 13:
+		l.INCLUDE_Action(localctx, actionIndex)
+
+	default:
+		panic("No registered action for: " + fmt.Sprint(ruleIndex))
+	}
+}
+
+func (l *CalcLexer) INCLUDE_Action(localctx RuleContext, actionIndex int) {
+	switch actionIndex {
+	case 0:
+		text := l.GetText()
+
+		for i := len(text) - 1; i >= 0; i-- {
+			if text[i] == ' ' {
+				l.Include(text[i+1:])
+
+				break
+			}
+		}
+
+	default:
+		panic("No registered action for: " + fmt.Sprint(actionIndex))
+	}
+}
@@ -0,0 +1,1244 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import "reflect"
+
+// The parser of the Calc grammar described with CalcLexer.
+var calcSerializedParserATN = []uint16{
+	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 3, 19, 112, 4, 2,
+	9, 2, 4, 3, 9, 3, 4, 4, 9, 4, 4, 5, 9, 5, 4, 6, 9, 6, 4, 7, 9, 7, 4, 8, 9, 8,
+	3, 2, 3, 2, 12, 2, 7, 2, 20, 10, 2, 11, 2, 14, 2, 21, 3, 2, 3, 2, 3, 3, 3, 3,
+	3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
+	3, 3, 12, 3, 7, 3, 43, 10, 3, 11, 3, 14, 3, 44, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
+	3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 5, 3, 61, 10, 3, 3, 4,
+	3, 4, 3, 4, 3, 4, 12, 4, 7, 4, 68, 10, 4, 11, 4, 14, 4, 69, 3, 4, 3, 4, 3, 5,
+	3, 5, 3, 5, 3, 5, 3, 5, 3, 5, 12, 5, 7, 5, 81, 10, 5, 11, 5, 14, 5, 82, 3, 6,
+	3, 6, 3, 6, 3, 6, 3, 6, 3, 6, 3, 6, 3, 6, 3, 6, 3, 6, 3, 6, 3, 6, 5, 6, 97,
+	10, 6, 3, 7, 3, 7, 6, 7, 101, 10, 7, 13, 7, 14, 7, 102, 3, 8, 3, 8, 3, 8, 3,
+	8, 5, 8, 109, 10, 8, 3, 8, 3, 8, 2, 2, 9, 2, 4, 6, 8, 10, 12, 14, 2, 2, 2,
+	118, 16, 17, 5, 4, 3, 2, 19, 16, 3, 2, 2, 2, 17, 20, 3, 2, 2, 2, 18, 19, 3, 2,
+	2, 2, 18, 22, 3, 2, 2, 2, 20, 21, 3, 2, 2, 2, 21, 18, 3, 2, 2, 2, 23, 24, 7,
+	2, 2, 3, 22, 23, 3, 2, 2, 2, 2, 18, 3, 2, 2, 2, 24, 3, 3, 2, 2, 2, 25, 26, 7,
+	13, 2, 2, 27, 28, 7, 4, 2, 2, 29, 30, 5, 8, 5, 2, 31, 32, 7, 5, 2, 2, 33, 34,
+	7, 3, 2, 2, 35, 36, 5, 8, 5, 2, 37, 38, 7, 6, 2, 2, 39, 40, 5, 8, 5, 2, 38,
+	39, 3, 2, 2, 2, 42, 37, 3, 2, 2, 2, 40, 43, 3, 2, 2, 2, 41, 42, 3, 2, 2, 2,
+	41, 45, 3, 2, 2, 2, 43, 44, 3, 2, 2, 2, 44, 41, 3, 2, 2, 2, 46, 47, 7, 5, 2,
+	2, 48, 49, 5, 6, 4, 2, 50, 51, 5, 14, 8, 2, 52, 53, 7, 12, 2, 2, 54, 55, 5, 4,
+	3, 2, 56, 57, 5, 8, 5, 2, 58, 59, 7, 5, 2, 2, 26, 27, 3, 2, 2, 2, 28, 29, 3,
+	2, 2, 2, 30, 31, 3, 2, 2, 2, 34, 35, 3, 2, 2, 2, 36, 41, 3, 2, 2, 2, 45, 46,
+	3, 2, 2, 2, 51, 52, 3, 2, 2, 2, 53, 54, 3, 2, 2, 2, 57, 58, 3, 2, 2, 2, 60,
+	25, 3, 2, 2, 2, 32, 61, 3, 2, 2, 2, 60, 33, 3, 2, 2, 2, 47, 61, 3, 2, 2, 2,
+	60, 48, 3, 2, 2, 2, 49, 61, 3, 2, 2, 2, 60, 50, 3, 2, 2, 2, 55, 61, 3, 2, 2,
+	2, 60, 56, 3, 2, 2, 2, 59, 61, 3, 2, 2, 2, 4, 60, 3, 2, 2, 2, 61, 5, 3, 2, 2,
+	2, 62, 63, 7, 10, 2, 2, 64, 65, 5, 4, 3, 2, 67, 64, 3, 2, 2, 2, 65, 68, 3, 2,
+	2, 2, 66, 67, 3, 2, 2, 2, 66, 70, 3, 2, 2, 2, 68, 69, 3, 2, 2, 2, 69, 66, 3,
+	2, 2, 2, 71, 72, 7, 11, 2, 2, 63, 66, 3, 2, 2, 2, 70, 71, 3, 2, 2, 2, 6, 62,
+	3, 2, 2, 2, 72, 7, 3, 2, 2, 2, 73, 74, 5, 10, 6, 2, 75, 76, 7, 7, 2, 2, 77,
+	78, 5, 10, 6, 2, 76, 77, 3, 2, 2, 2, 80, 75, 3, 2, 2, 2, 78, 81, 3, 2, 2, 2,
+	79, 80, 3, 2, 2, 2, 79, 83, 3, 2, 2, 2, 81, 82, 3, 2, 2, 2, 82, 79, 3, 2, 2,
+	2, 74, 79, 3, 2, 2, 2, 8, 73, 3, 2, 2, 2, 83, 9, 3, 2, 2, 2, 84, 85, 7, 13, 2,
+	2, 86, 87, 7, 14, 2, 2, 88, 89, 5, 12, 7, 2, 90, 91, 7, 8, 2, 2, 92, 93, 5, 8,
+	5, 2, 94, 95, 7, 9, 2, 2, 91, 92, 3, 2, 2, 2, 93, 94, 3, 2, 2, 2, 96, 84, 3,
+	2, 2, 2, 85, 97, 3, 2, 2, 2, 96, 86, 3, 2, 2, 2, 87, 97, 3, 2, 2, 2, 96, 88,
+	3, 2, 2, 2, 89, 97, 3, 2, 2, 2, 96, 90, 3, 2, 2, 2, 95, 97, 3, 2, 2, 2, 10,
+	96, 3, 2, 2, 2, 97, 11, 3, 2, 2, 2, 98, 99, 5, 14, 8, 2, 100, 98, 3, 2, 2, 2,
+	99, 101, 3, 2, 2, 2, 101, 102, 3, 2, 2, 2, 102, 100, 3, 2, 2, 2, 102, 103, 3,
+	2, 2, 2, 12, 100, 3, 2, 2, 2, 103, 13, 3, 2, 2, 2, 104, 105, 7, 15, 2, 2, 106,
+	107, 7, 18, 2, 2, 108, 106, 3, 2, 2, 2, 107, 109, 3, 2, 2, 2, 108, 109, 3, 2,
+	2, 2, 110, 111, 7, 19, 2, 2, 105, 108, 3, 2, 2, 2, 109, 110, 3, 2, 2, 2, 14,
+	104, 3, 2, 2, 2, 111, 15, 3, 2, 2, 2, 15, 19, 18, 42, 41, 60, 67, 66, 80, 79,
+	96, 100, 102, 108,
+}
+
+var calcParserATN = NewATNDeserializer(nil).DeserializeFromUInt16(calcSerializedParserATN)
+
+var calcRuleNames = []string{
+	"prog", "stat", "block", "expr", "atom", "text", "str",
+}
+
+type CalcParser struct {
+	*BaseParser
+}
+
+func NewCalcParser(input TokenStream) *CalcParser {
+	var decisionToDFA = make([]*DFA, len(calcParserATN.DecisionToState))
+	var sharedContextCache = NewPredictionContextCache()
+
+	for index, ds := range calcParserATN.DecisionToState {
+		decisionToDFA[index] = NewDFA(ds, index)
+	}
+
+	this := new(CalcParser)
+
+	this.BaseParser = NewBaseParser(input)
+
+	this.Interpreter = NewParserATNSimulator(this, calcParserATN, decisionToDFA, sharedContextCache)
+	this.RuleNames = calcRuleNames
+	this.LiteralNames = calcLiteralNames
+	this.SymbolicNames = calcSymbolicNames
+	this.GrammarFileName = "Calc.g4"
+
+	return this
+}
+
+// CalcParser tokens.
+const (
+	CalcParserEOF         = TokenEOF
+	CalcParserPRINT       = 1
+	CalcParserASSIGN      = 2
+	CalcParserSEMI        = 3
+	CalcParserCOMMA       = 4
+	CalcParserPLUS        = 5
+	CalcParserLPAREN      = 6
+	CalcParserRPAREN      = 7
+	CalcParserLBRACE      = 8
+	CalcParserRBRACE      = 9
+	CalcParserCOLON       = 10
+	CalcParserID          = 11
+	CalcParserINT         = 12
+	CalcParserQUOTE       = 13
+	CalcParserINCLUDE     = 14
+	CalcParserWS          = 15
+	CalcParserSTRING_TEXT = 16
+	CalcParserSTRING_END  = 17
+)
+
+// CalcParser rules.
+const (
+	CalcParserRULE_prog  = 0
+	CalcParserRULE_stat  = 1
+	CalcParserRULE_block = 2
+	CalcParserRULE_expr  = 3
+	CalcParserRULE_atom  = 4
+	CalcParserRULE_text  = 5
+	CalcParserRULE_str   = 6
+)
+
+// CalcListener is a complete listener for a parse tree produced by
+// CalcParser.
+type CalcListener interface {
+	ParseTreeListener
+
+	EnterProg(c *ProgContext)
+	EnterStat(c *StatContext)
+	EnterBlock(c *BlockContext)
+	EnterExpr(c *ExprContext)
+	EnterAtom(c *AtomContext)
+	EnterText(c *TextContext)
+	EnterStr(c *StrContext)
+
+	ExitProg(c *ProgContext)
+	ExitStat(c *StatContext)
+	ExitBlock(c *BlockContext)
+	ExitExpr(c *ExprContext)
+	ExitAtom(c *AtomContext)
+	ExitText(c *TextContext)
+	ExitStr(c *StrContext)
+}
+
+// BaseCalcListener is a complete listener for a parse tree produced by
+// CalcParser.
+type BaseCalcListener struct{}
+
+var _ CalcListener = &BaseCalcListener{}
+
+// VisitTerminal is called when a terminal node is visited.
+func (s *BaseCalcListener) VisitTerminal(node TerminalNode) {}
+
+// VisitErrorNode is called when an error node is visited.
+func (s *BaseCalcListener) VisitErrorNode(node ErrorNode) {}
+
+// EnterEveryRule is called when any rule is entered.
+func (s *BaseCalcListener) EnterEveryRule(ctx ParserRuleContext) {}
+
+// ExitEveryRule is called when any rule is exited.
+func (s *BaseCalcListener) ExitEveryRule(ctx ParserRuleContext) {}
+
+// EnterProg is called when production prog is entered.
+func (s *BaseCalcListener) EnterProg(ctx *ProgContext) {}
+
+// ExitProg is called when production prog is exited.
+func (s *BaseCalcListener) ExitProg(ctx *ProgContext) {}
+
+// EnterStat is called when production stat is entered.
+func (s *BaseCalcListener) EnterStat(ctx *StatContext) {}
+
+// ExitStat is called when production stat is exited.
+func (s *BaseCalcListener) ExitStat(ctx *StatContext) {}
+
+// EnterBlock is called when production block is entered.
+func (s *BaseCalcListener) EnterBlock(ctx *BlockContext) {}
+
+// ExitBlock is called when production block is exited.
+func (s *BaseCalcListener) ExitBlock(ctx *BlockContext) {}
+
+// EnterExpr is called when production expr is entered.
+func (s *BaseCalcListener) EnterExpr(ctx *ExprContext) {}
+
+// ExitExpr is called when production expr is exited.
+func (s *BaseCalcListener) ExitExpr(ctx *ExprContext) {}
+
+// EnterAtom is called when production atom is entered.
+func (s *BaseCalcListener) EnterAtom(ctx *AtomContext) {}
+
+// ExitAtom is called when production atom is exited.
+func (s *BaseCalcListener) ExitAtom(ctx *AtomContext) {}
+
+// EnterText is called when production text is entered.
+func (s *BaseCalcListener) EnterText(ctx *TextContext) {}
+
+// ExitText is called when production text is exited.
+func (s *BaseCalcListener) ExitText(ctx *TextContext) {}
+
+// EnterStr is called when production str is entered.
+func (s *BaseCalcListener) EnterStr(ctx *StrContext) {}
+
+// ExitStr is called when production str is exited.
+func (s *BaseCalcListener) ExitStr(ctx *StrContext) {}
+
+// IProgContext is an interface to support dynamic dispatch.
+type IProgContext interface {
+	ParserRuleContext
+
+	// GetParser returns the parser.
+	GetParser() Parser
+
+	// IsProgContext differentiates from other interfaces.
+	IsProgContext()
+}
+
+type ProgContext struct {
+	*BaseParserRuleContext
+	parser Parser
+}
+
+func NewEmptyProgContext() *ProgContext {
+	var p = new(ProgContext)
+	p.BaseParserRuleContext = NewBaseParserRuleContext(nil, -1)
+	p.RuleIndex = CalcParserRULE_prog
+	return p
+}
+
+func (*ProgContext) IsProgContext() {}
+
+func NewProgContext(parser Parser, parent ParserRuleContext, invokingState int) *ProgContext {
+	var p = new(ProgContext)
+
+	p.BaseParserRuleContext = NewBaseParserRuleContext(parent, invokingState)
+
+	p.parser = parser
+	p.RuleIndex = CalcParserRULE_prog
+
+	return p
+}
+
+func (s *ProgContext) GetParser() Parser { return s.parser }
+
+func (s *ProgContext) EOF() TerminalNode {
+	return s.GetToken(CalcParserEOF, 0)
+}
+
+func (s *ProgContext) AllStat() []IStatContext {
+	var ts = s.GetTypedRuleContexts(reflect.TypeOf((*IStatContext)(nil)).Elem())
+	var tst = make([]IStatContext, len(ts))
+
+	for i, t := range ts {
+		if t != nil {
+			tst[i] = t.(IStatContext)
+		}
+	}
+
+	return tst
+}
+
+func (s *ProgContext) Stat(i int) IStatContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IStatContext)(nil)).Elem(), i)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IStatContext)
+}
+
+func (s *ProgContext) GetRuleContext() RuleContext {
+	return s
+}
+
+func (s *ProgContext) ToStringTree(ruleNames []string, recog Recognizer) string {
+	return TreesStringTree(s, ruleNames, recog)
+}
+
+func (s *ProgContext) EnterRule(listener ParseTreeListener) {
+	if listenerT, ok := listener.(CalcListener); ok {
+		listenerT.EnterProg(s)
+	}
+}
+
+func (s *ProgContext) ExitRule(listener ParseTreeListener) {
+	if listenerT, ok := listener.(CalcListener); ok {
+		listenerT.ExitProg(s)
+	}
+}
+
+func (p *CalcParser) Prog() (localctx IProgContext) {
+	localctx = NewProgContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 0, CalcParserRULE_prog)
+	var _la int
+
+	defer func() {
+		p.ExitRule()
+	}()
+
+	defer func() {
+		if err := recover(); err != nil {
+			if v, ok := err.(RecognitionException); ok {
+				localctx.SetException(v)
+				p.GetErrorHandler().ReportError(p, v)
+				p.GetErrorHandler().Recover(p, v)
+			} else {
+				panic(err)
+			}
+		}
+	}()
+
+	p.EnterOuterAlt(localctx, 1)
+	p.SetState(16)
+	p.GetErrorHandler().Sync(p)
+	_la = p.GetTokenStream().LA(1)
+
+	for calcStatStart(_la) {
+		{
+			p.SetState(14)
+			p.Stat()
+		}
+
+		p.SetState(19)
+		p.GetErrorHandler().Sync(p)
+		_la = p.GetTokenStream().LA(1)
+	}
+	{
+		p.SetState(21)
+		p.Match(CalcParserEOF)
+	}
+
+	return localctx
+}
+
+// calcStatStart returns true if tokenType can start a stat.
+func calcStatStart(tokenType int) bool {
+	switch tokenType {
+	case CalcParserPRINT, CalcParserLPAREN, CalcParserLBRACE, CalcParserID, CalcParserINT, CalcParserQUOTE:
+		return true
+	}
+
+	return false
+}
+
+// IStatContext is an interface to support dynamic dispatch.
+type IStatContext interface {
+	ParserRuleContext
+
+	// GetParser returns the parser.
+	GetParser() Parser
+
+	// GetId returns the id token.
+	GetId() Token
+
+	// SetId sets the id token.
+	SetId(Token)
+
+	// GetValue returns the value rule contexts.
+	GetValue() IExprContext
+
+	// Get_expr returns the _expr rule contexts.
+	Get_expr() IExprContext
+
+	// SetValue sets the value rule contexts.
+	SetValue(IExprContext)
+
+	// Set_expr sets the _expr rule contexts.
+	Set_expr(IExprContext)
+
+	// GetArgs returns the args rule context list.
+	GetArgs() []IExprContext
+
+	// SetArgs sets the args rule context list.
+	SetArgs([]IExprContext)
+
+	// IsStatContext differentiates from other interfaces.
+	IsStatContext()
+}
+
+type StatContext struct {
+	*BaseParserRuleContext
+	parser Parser
+	id     Token
+	value  IExprContext
+	_expr  IExprContext
+	args   []IExprContext
+}
+
+func NewEmptyStatContext() *StatContext {
+	var p = new(StatContext)
+	p.BaseParserRuleContext = NewBaseParserRuleContext(nil, -1)
+	p.RuleIndex = CalcParserRULE_stat
+	return p
+}
+
+func (*StatContext) IsStatContext() {}
+
+func NewStatContext(parser Parser, parent ParserRuleContext, invokingState int) *StatContext {
+	var p = new(StatContext)
+
+	p.BaseParserRuleContext = NewBaseParserRuleContext(parent, invokingState)
+
+	p.parser = parser
+	p.RuleIndex = CalcParserRULE_stat
+
+	return p
+}
+
+func (s *StatContext) GetParser() Parser { return s.parser }
+
+func (s *StatContext) GetId() Token { return s.id }
+
+func (s *StatContext) SetId(v Token) { s.id = v }
+
+func (s *StatContext) GetValue() IExprContext { return s.value }
+
+func (s *StatContext) Get_expr() IExprContext { return s._expr }
+
+func (s *StatContext) SetValue(v IExprContext) { s.value = v }
+
+func (s *StatContext) Set_expr(v IExprContext) { s._expr = v }
+
+func (s *StatContext) GetArgs() []IExprContext { return s.args }
+
+func (s *StatContext) SetArgs(v []IExprContext) { s.args = v }
+
+func (s *StatContext) ID() TerminalNode {
+	return s.GetToken(CalcParserID, 0)
+}
+
+func (s *StatContext) ASSIGN() TerminalNode {
+	return s.GetToken(CalcParserASSIGN, 0)
+}
+
+func (s *StatContext) SEMI() TerminalNode {
+	return s.GetToken(CalcParserSEMI, 0)
+}
+
+func (s *StatContext) AllExpr() []IExprContext {
+	var ts = s.GetTypedRuleContexts(reflect.TypeOf((*IExprContext)(nil)).Elem())
+	var tst = make([]IExprContext, len(ts))
+
+	for i, t := range ts {
+		if t != nil {
+			tst[i] = t.(IExprContext)
+		}
+	}
+
+	return tst
+}
+
+func (s *StatContext) Expr(i int) IExprContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IExprContext)(nil)).Elem(), i)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IExprContext)
+}
+
+func (s *StatContext) PRINT() TerminalNode {
+	return s.GetToken(CalcParserPRINT, 0)
+}
+
+func (s *StatContext) Block() IBlockContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IBlockContext)(nil)).Elem(), 0)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IBlockContext)
+}
+
+func (s *StatContext) Str() IStrContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IStrContext)(nil)).Elem(), 0)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IStrContext)
+}
+
+func (s *StatContext) Stat() IStatContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IStatContext)(nil)).Elem(), 0)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IStatContext)
+}
+
+func (s *StatContext) GetRuleContext() RuleContext {
+	return s
+}
+
+func (s *StatContext) ToStringTree(ruleNames []string, recog Recognizer) string {
+	return TreesStringTree(s, ruleNames, recog)
+}
+
+func (s *StatContext) EnterRule(listener ParseTreeListener) {
+	if listenerT, ok := listener.(CalcListener); ok {
+		listenerT.EnterStat(s)
+	}
+}
+
+func (s *StatContext) ExitRule(listener ParseTreeListener) {
+	if listenerT, ok := listener.(CalcListener); ok {
+		listenerT.ExitStat(s)
+	}
+}
+
+func (p *CalcParser) Stat() (localctx IStatContext) {
+	localctx = NewStatContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 2, CalcParserRULE_stat)
+	var _la int
+
+	defer func() {
+		p.ExitRule()
+	}()
+
+	defer func() {
+		if err := recover(); err != nil {
+			if v, ok := err.(RecognitionException); ok {
+				localctx.SetException(v)
+				p.GetErrorHandler().ReportError(p, v)
+				p.GetErrorHandler().Recover(p, v)
+			} else {
+				panic(err)
+			}
+		}
+	}()
+
+	p.SetState(58)
+	p.GetErrorHandler().Sync(p)
+
+	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 4, p.GetParserRuleContext()) {
+	case 1:
+		p.EnterOuterAlt(localctx, 1)
+		{
+			p.SetState(23)
+
+			var _m = p.Match(CalcParserID)
+
+			localctx.(*StatContext).id = _m
+		}
+		{
+			p.SetState(25)
+			p.Match(CalcParserASSIGN)
+		}
+		{
+			p.SetState(27)
+
+			var _x = p.Expr(len((func() string {
+				if localctx.(*StatContext).GetId() == nil {
+					return ""
+				} else {
+					return localctx.(*StatContext).GetId().GetText()
+				}
+			}())))
+
+			localctx.(*StatContext).value = _x
+		}
+		{
+			p.SetState(29)
+			p.Match(CalcParserSEMI)
+		}
+
+	case 2:
+		p.EnterOuterAlt(localctx, 2)
+		{
+			p.SetState(31)
+			p.Match(CalcParserPRINT)
+		}
+		{
+			p.SetState(33)
+
+			var _x = p.Expr(0)
+
+			localctx.(*StatContext)._expr = _x
+		}
+		localctx.(*StatContext).args = append(localctx.(*StatContext).args, localctx.(*StatContext)._expr)
+		p.SetState(39)
+		p.GetErrorHandler().Sync(p)
+		_la = p.GetTokenStream().LA(1)
+
+		for _la == CalcParserCOMMA {
+			{
+				p.SetState(35)
+				p.Match(CalcParserCOMMA)
+			}
+			{
+				p.SetState(37)
+
+				var _x = p.Expr(0)
+
+				localctx.(*StatContext)._expr = _x
+			}
+			localctx.(*StatContext).args = append(localctx.(*StatContext).args, localctx.(*StatContext)._expr)
+
+			p.SetState(42)
+			p.GetErrorHandler().Sync(p)
+			_la = p.GetTokenStream().LA(1)
+		}
+		{
+			p.SetState(44)
+			p.Match(CalcParserSEMI)
+		}
+
+	case 3:
+		p.EnterOuterAlt(localctx, 3)
+		{
+			p.SetState(46)
+			p.Block()
+		}
+
+	case 4:
+		p.EnterOuterAlt(localctx, 4)
+		{
+			p.SetState(48)
+			p.Str()
+		}
+		{
+			p.SetState(50)
+			p.Match(CalcParserCOLON)
+		}
+		{
+			p.SetState(52)
+			p.Stat()
+		}
+
+	case 5:
+		p.EnterOuterAlt(localctx, 5)
+		{
+			p.SetState(54)
+			p.Expr(0)
+		}
+		{
+			p.SetState(56)
+			p.Match(CalcParserSEMI)
+		}
+
+	}
+
+	return localctx
+}
+
+// IBlockContext is an interface to support dynamic dispatch.
+type IBlockContext interface {
+	ParserRuleContext
+
+	// GetParser returns the parser.
+	GetParser() Parser
+
+	// IsBlockContext differentiates from other interfaces.
+	IsBlockContext()
+}
+
+type BlockContext struct {
+	*BaseParserRuleContext
+	parser Parser
+}
+
+func NewEmptyBlockContext() *BlockContext {
+	var p = new(BlockContext)
+	p.BaseParserRuleContext = NewBaseParserRuleContext(nil, -1)
+	p.RuleIndex = CalcParserRULE_block
+	return p
+}
+
+func (*BlockContext) IsBlockContext() {}
+
+func NewBlockContext(parser Parser, parent ParserRuleContext, invokingState int) *BlockContext {
+	var p = new(BlockContext)
+
+	p.BaseParserRuleContext = NewBaseParserRuleContext(parent, invokingState)
+
+	p.parser = parser
+	p.RuleIndex = CalcParserRULE_block
+
+	return p
+}
+
+func (s *BlockContext) GetParser() Parser { return s.parser }
+
+func (s *BlockContext) LBRACE() TerminalNode {
+	return s.GetToken(CalcParserLBRACE, 0)
+}
+
+func (s *BlockContext) RBRACE() TerminalNode {
+	return s.GetToken(CalcParserRBRACE, 0)
+}
+
+func (s *BlockContext) AllStat() []IStatContext {
+	var ts = s.GetTypedRuleContexts(reflect.TypeOf((*IStatContext)(nil)).Elem())
+	var tst = make([]IStatContext, len(ts))
+
+	for i, t := range ts {
+		if t != nil {
+			tst[i] = t.(IStatContext)
+		}
+	}
+
+	return tst
+}
+
+func (s *BlockContext) GetRuleContext() RuleContext {
+	return s
+}
+
+func (s *BlockContext) ToStringTree(ruleNames []string, recog Recognizer) string {
+	return TreesStringTree(s, ruleNames, recog)
+}
+
+func (s *BlockContext) EnterRule(listener ParseTreeListener) {
+	if listenerT, ok := listener.(CalcListener); ok {
+		listenerT.EnterBlock(s)
+	}
+}
+
+func (s *BlockContext) ExitRule(listener ParseTreeListener) {
+	if listenerT, ok := listener.(CalcListener); ok {
+		listenerT.ExitBlock(s)
+	}
+}
+
+func (p *CalcParser) Block() (localctx IBlockContext) {
+	localctx = NewBlockContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 4, CalcParserRULE_block)
+	var _la int
+
+	defer func() {
+		p.ExitRule()
+	}()
+
+	defer func() {
+		if err := recover(); err != nil {
+			if v, ok := err.(RecognitionException); ok {
+				localctx.SetException(v)
+				p.GetErrorHandler().ReportError(p, v)
+				p.GetErrorHandler().Recover(p, v)
+			} else {
+				panic(err)
+			}
+		}
+	}()
+
+	p.EnterOuterAlt(localctx, 1)
+	{
+		p.SetState(60)
+		p.Match(CalcParserLBRACE)
+	}
+	p.SetState(64)
+	p.GetErrorHandler().Sync(p)
+	_la = p.GetTokenStream().LA(1)
+
+	for calcStatStart(_la) {
+		{
+			p.SetState(62)
+			p.Stat()
+		}
+
+		p.SetState(67)
+		p.GetErrorHandler().Sync(p)
+		_la = p.GetTokenStream().LA(1)
+	}
+	{
+		p.SetState(69)
+		p.Match(CalcParserRBRACE)
+	}
+
+	return localctx
+}
+
+// IExprContext is an interface to support dynamic dispatch.
+type IExprContext interface {
+	ParserRuleContext
+
+	// GetParser returns the parser.
+	GetParser() Parser
+
+	// GetP returns the p attribute.
+	GetP() int
+
+	// SetP sets the p attribute.
+	SetP(int)
+
+	// IsExprContext differentiates from other interfaces.
+	IsExprContext()
+}
+
+type ExprContext struct {
+	*BaseParserRuleContext
+	parser Parser
+	p      int
+}
+
+func NewEmptyExprContext() *ExprContext {
+	var p = new(ExprContext)
+	p.BaseParserRuleContext = NewBaseParserRuleContext(nil, -1)
+	p.RuleIndex = CalcParserRULE_expr
+	return p
+}
+
+func (*ExprContext) IsExprContext() {}
+
+func NewExprContext(parser Parser, parent ParserRuleContext, invokingState int, p int) *ExprContext {
+	var p1 = new(ExprContext)
+
+	p1.BaseParserRuleContext = NewBaseParserRuleContext(parent, invokingState)
+
+	p1.parser = parser
+	p1.RuleIndex = CalcParserRULE_expr
+
+	p1.p = p
+
+	return p1
+}
+
+func (s *ExprContext) GetParser() Parser { return s.parser }
+
+func (s *ExprContext) GetP() int { return s.p }
+
+func (s *ExprContext) SetP(v int) { s.p = v }
+
+func (s *ExprContext) AllAtom() []IAtomContext {
+	var ts = s.GetTypedRuleContexts(reflect.TypeOf((*IAtomContext)(nil)).Elem())
+	var tst = make([]IAtomContext, len(ts))
+
+	for i, t := range ts {
+		if t != nil {
+			tst[i] = t.(IAtomContext)
+		}
+	}
+
+	return tst
+}
+
+func (s *ExprContext) GetRuleContext() RuleContext {
+	return s
+}
+
+func (s *ExprContext) ToStringTree(ruleNames []string, recog Recognizer) string {
+	return TreesStringTree(s, ruleNames, recog)
+}
+
+func (s *ExprContext) EnterRule(listener ParseTreeListener) {
+	if listenerT, ok := listener.(CalcListener); ok {
+		listenerT.EnterExpr(s)
+	}
+}
+
+func (s *ExprContext) ExitRule(listener ParseTreeListener) {
+	if listenerT, ok := listener.(CalcListener); ok {
+		listenerT.ExitExpr(s)
+	}
+}
+
+func (p *CalcParser) Expr(_p int) (localctx IExprContext) {
+	localctx = NewExprContext(p, p.GetParserRuleContext(), p.GetState(), _p)
+	p.EnterRule(localctx, 6, CalcParserRULE_expr)
+	var _la int
+
+	defer func() {
+		p.ExitRule()
+	}()
+
+	defer func() {
+		if err := recover(); err != nil {
+			if v, ok := err.(RecognitionException); ok {
+				localctx.SetException(v)
+				p.GetErrorHandler().ReportError(p, v)
+				p.GetErrorHandler().Recover(p, v)
+			} else {
+				panic(err)
+			}
+		}
+	}()
+
+	p.EnterOuterAlt(localctx, 1)
+	{
+		p.SetState(71)
+		p.Atom()
+	}
+	p.SetState(77)
+	p.GetErrorHandler().Sync(p)
+	_la = p.GetTokenStream().LA(1)
+
+	for _la == CalcParserPLUS {
+		{
+			p.SetState(73)
+			p.Match(CalcParserPLUS)
+		}
+		{
+			p.SetState(75)
+			p.Atom()
+		}
+
+		p.SetState(80)
+		p.GetErrorHandler().Sync(p)
+		_la = p.GetTokenStream().LA(1)
+	}
+
+	return localctx
+}
+
+// IAtomContext is an interface to support dynamic dispatch.
+type IAtomContext interface {
+	ParserRuleContext
+
+	// GetParser returns the parser.
+	GetParser() Parser
+
+	// IsAtomContext differentiates from other interfaces.
+	IsAtomContext()
+}
+
+type AtomContext struct {
+	*BaseParserRuleContext
+	parser Parser
+}
+
+func NewEmptyAtomContext() *AtomContext {
+	var p = new(AtomContext)
+	p.BaseParserRuleContext = NewBaseParserRuleContext(nil, -1)
+	p.RuleIndex = CalcParserRULE_atom
+	return p
+}
+
+func (*AtomContext) IsAtomContext() {}
+
+func NewAtomContext(parser Parser, parent ParserRuleContext, invokingState int) *AtomContext {
+	var p = new(AtomContext)
+
+	p.BaseParserRuleContext = NewBaseParserRuleContext(parent, invokingState)
+
+	p.parser = parser
+	p.RuleIndex = CalcParserRULE_atom
+
+	return p
+}
+
+func (s *AtomContext) GetParser() Parser { return s.parser }
+
+func (s *AtomContext) ID() TerminalNode {
+	return s.GetToken(CalcParserID, 0)
+}
+
+func (s *AtomContext) GetRuleContext() RuleContext {
+	return s
+}
+
+func (s *AtomContext) ToStringTree(ruleNames []string, recog Recognizer) string {
+	return TreesStringTree(s, ruleNames, recog)
+}
+
+func (s *AtomContext) EnterRule(listener ParseTreeListener) {
+	if listenerT, ok := listener.(CalcListener); ok {
+		listenerT.EnterAtom(s)
+	}
+}
+
+func (s *AtomContext) ExitRule(listener ParseTreeListener) {
+	if listenerT, ok := listener.(CalcListener); ok {
+		listenerT.ExitAtom(s)
+	}
+}
+
+func (p *CalcParser) Atom() (localctx IAtomContext) {
+	localctx = NewAtomContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 8, CalcParserRULE_atom)
+
+	defer func() {
+		p.ExitRule()
+	}()
+
+	defer func() {
+		if err := recover(); err != nil {
+			if v, ok := err.(RecognitionException); ok {
+				localctx.SetException(v)
+				p.GetErrorHandler().ReportError(p, v)
+				p.GetErrorHandler().Recover(p, v)
+			} else {
+				panic(err)
+			}
+		}
+	}()
+
+	p.SetState(94)
+	p.GetErrorHandler().Sync(p)
+
+	switch p.GetTokenStream().LA(1) {
+	case CalcParserID:
+		p.EnterOuterAlt(localctx, 1)
+		{
+			p.SetState(82)
+			p.Match(CalcParserID)
+		}
+
+	case CalcParserINT:
+		p.EnterOuterAlt(localctx, 2)
+		{
+			p.SetState(84)
+			p.Match(CalcParserINT)
+		}
+
+	case CalcParserQUOTE:
+		p.EnterOuterAlt(localctx, 3)
+		{
+			p.SetState(86)
+			p.Text()
+		}
+
+	case CalcParserLPAREN:
+		p.EnterOuterAlt(localctx, 4)
+		{
+			p.SetState(88)
+			p.Match(CalcParserLPAREN)
+		}
+		{
+			p.SetState(90)
+			p.Expr(0)
+		}
+		{
+			p.SetState(92)
+			p.Match(CalcParserRPAREN)
+		}
+
+	default:
+		panic(NewNoViableAltException(p, nil, nil, nil, nil, nil))
+	}
+
+	return localctx
+}
+
+// ITextContext is an interface to support dynamic dispatch.
+type ITextContext interface {
+	ParserRuleContext
+
+	// GetParser returns the parser.
+	GetParser() Parser
+
+	// IsTextContext differentiates from other interfaces.
+	IsTextContext()
+}
+
+type TextContext struct {
+	*BaseParserRuleContext
+	parser Parser
+}
+
+func NewEmptyTextContext() *TextContext {
+	var p = new(TextContext)
+	p.BaseParserRuleContext = NewBaseParserRuleContext(nil, -1)
+	p.RuleIndex = CalcParserRULE_text
+	return p
+}
+
+func (*TextContext) IsTextContext() {}
+
+func NewTextContext(parser Parser, parent ParserRuleContext, invokingState int) *TextContext {
+	var p = new(TextContext)
+
+	p.BaseParserRuleContext = NewBaseParserRuleContext(parent, invokingState)
+
+	p.parser = parser
+	p.RuleIndex = CalcParserRULE_text
+
+	return p
+}
+
+func (s *TextContext) GetParser() Parser { return s.parser }
+
+func (s *TextContext) GetRuleContext() RuleContext {
+	return s
+}
+
+func (s *TextContext) ToStringTree(ruleNames []string, recog Recognizer) string {
+	return TreesStringTree(s, ruleNames, recog)
+}
+
+func (s *TextContext) EnterRule(listener ParseTreeListener) {
+	if listenerT, ok := listener.(CalcListener); ok {
+		listenerT.EnterText(s)
+	}
+}
+
+func (s *TextContext) ExitRule(listener ParseTreeListener) {
+	if listenerT, ok := listener.(CalcListener); ok {
+		listenerT.ExitText(s)
+	}
+}
+
+func (p *CalcParser) Text() (localctx ITextContext) {
+	localctx = NewTextContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 10, CalcParserRULE_text)
+	var _la int
+
+	defer func() {
+		p.ExitRule()
+	}()
+
+	defer func() {
+		if err := recover(); err != nil {
+			if v, ok := err.(RecognitionException); ok {
+				localctx.SetException(v)
+				p.GetErrorHandler().ReportError(p, v)
+				p.GetErrorHandler().Recover(p, v)
+			} else {
+				panic(err)
+			}
+		}
+	}()
+
+	p.EnterOuterAlt(localctx, 1)
+	p.SetState(98)
+	p.GetErrorHandler().Sync(p)
+	_la = p.GetTokenStream().LA(1)
+
+	for ok := true; ok; ok = _la == CalcParserQUOTE {
+		{
+			p.SetState(96)
+			p.Str()
+		}
+
+		p.SetState(100)
+		p.GetErrorHandler().Sync(p)
+		_la = p.GetTokenStream().LA(1)
+	}
+
+	return localctx
+}
+
+// IStrContext is an interface to support dynamic dispatch.
+type IStrContext interface {
+	ParserRuleContext
+
+	// GetParser returns the parser.
+	GetParser() Parser
+
+	// IsStrContext differentiates from other interfaces.
+	IsStrContext()
+}
+
+type StrContext struct {
+	*BaseParserRuleContext
+	parser Parser
+}
+
+func NewEmptyStrContext() *StrContext {
+	var p = new(StrContext)
+	p.BaseParserRuleContext = NewBaseParserRuleContext(nil, -1)
+	p.RuleIndex = CalcParserRULE_str
+	return p
+}
+
+func (*StrContext) IsStrContext() {}
+
+func NewStrContext(parser Parser, parent ParserRuleContext, invokingState int) *StrContext {
+	var p = new(StrContext)
+
+	p.BaseParserRuleContext = NewBaseParserRuleContext(parent, invokingState)
+
+	p.parser = parser
+	p.RuleIndex = CalcParserRULE_str
+
+	return p
+}
+
+func (s *StrContext) GetParser() Parser { return s.parser }
+
+func (s *StrContext) GetRuleContext() RuleContext {
+	return s
+}
+
+func (s *StrContext) ToStringTree(ruleNames []string, recog Recognizer) string {
+	return TreesStringTree(s, ruleNames, recog)
+}
+
+func (s *StrContext) EnterRule(listener ParseTreeListener) {
+	if listenerT, ok := listener.(CalcListener); ok {
+		listenerT.EnterStr(s)
+	}
+}
+
+func (s *StrContext) ExitRule(listener ParseTreeListener) {
+	if listenerT, ok := listener.(CalcListener); ok {
+		listenerT.ExitStr(s)
+	}
+}
+
+func (p *CalcParser) Str() (localctx IStrContext) {
+	localctx = NewStrContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 12, CalcParserRULE_str)
+	var _la int
+
+	defer func() {
+		p.ExitRule()
+	}()
+
+	defer func() {
+		if err := recover(); err != nil {
+			if v, ok := err.(RecognitionException); ok {
+				localctx.SetException(v)
+				p.GetErrorHandler().ReportError(p, v)
+				p.GetErrorHandler().Recover(p, v)
+			} else {
+				panic(err)
+			}
+		}
+	}()
+
+	p.EnterOuterAlt(localctx, 1)
+	{
+		p.SetState(102)
+		p.Match(CalcParserQUOTE)
+	}
+	p.SetState(106)
+	p.GetErrorHandler().Sync(p)
+	_la = p.GetTokenStream().LA(1)
+
+	if _la == CalcParserSTRING_TEXT {
+		{
+			p.SetState(104)
+			p.Match(CalcParserSTRING_TEXT)
+		}
+	}
+	{
+		p.SetState(108)
+		p.Match(CalcParserSTRING_END)
+	}
+
+	return localctx
+}
+
+// parseCalc parses text with a CalcLexer and CalcParser, reporting syntax
+// errors to no listener, and returns the parser and the tree.
+func parseCalc(text string) (*CalcParser, IProgContext) {
+	lexer := NewCalcLexer(NewInputStream(text))
+	p := NewCalcParser(NewCommonTokenStream(lexer, TokenDefaultChannel))
+
+	p.RemoveErrorListeners()
+
+	return p, p.Prog()
+}
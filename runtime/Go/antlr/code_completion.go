@@ -0,0 +1,493 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+// CodeCompletionCore computes what can come next at a caret position by
+// walking the parser ATN over the tokens before the caret, in the manner of
+// antlr4-c3. The parser is only used for its ATN and token stream; it does not
+// have to have parsed anything. Semantic predicates are treated as true.
+type CodeCompletionCore struct {
+	parser         Parser
+	atn            *ATN
+	analyzer       *LL1Analyzer
+	preferredRules map[int]bool
+	ignoredTokens  map[int]bool
+
+	tokens     []int
+	candidates *CompletionCandidates
+
+	// followSets caches the follow sets of each rule start state and
+	// shortcuts the end positions of each rule, keyed by rule index, start
+	// token and precedence.
+	followSets map[int]*completionFollowSets
+	shortcuts  map[completionShortcut]map[int]bool
+}
+
+// CompletionCandidates is the result of CodeCompletionCore.CollectCandidates.
+//
+// Tokens maps each candidate token type to the token types that must follow
+// it, which is empty if there are none or they are ambiguous. Rules maps each
+// candidate preferred rule to the rule indexes of the invocation path that
+// leads to it, outermost rule first. When candidates are collected for a
+// context, the path starts with the rules enclosing that context as reported by
+// GetRuleInvocationStack.
+type CompletionCandidates struct {
+	Tokens map[int][]int
+	Rules  map[int][]int
+}
+
+type completionFollowSet struct {
+	set       *IntervalSet
+	path      []int
+	following []int
+}
+
+type completionFollowSets struct {
+	sets     []*completionFollowSet
+	combined *IntervalSet
+}
+
+type completionShortcut struct {
+	ruleIndex  int
+	tokenIndex int
+	precedence int
+}
+
+type completionPipelineEntry struct {
+	state      ATNState
+	tokenIndex int
+}
+
+func NewCodeCompletionCore(parser Parser) *CodeCompletionCore {
+	return &CodeCompletionCore{
+		parser:         parser,
+		atn:            parser.GetATN(),
+		analyzer:       NewLL1Analyzer(parser.GetATN()),
+		preferredRules: make(map[int]bool),
+		ignoredTokens:  make(map[int]bool),
+		followSets:     make(map[int]*completionFollowSets),
+	}
+}
+
+// SetPreferredRules sets the rules that are reported as candidates instead of
+// the tokens they start with, such as an identifier rule for which the caller
+// supplies symbol names.
+func (c *CodeCompletionCore) SetPreferredRules(ruleIndexes ...int) {
+	c.preferredRules = make(map[int]bool)
+
+	for _, r := range ruleIndexes {
+		c.preferredRules[r] = true
+	}
+}
+
+// SetIgnoredTokens sets the token types that are never reported as
+// candidates, such as operators and punctuation.
+func (c *CodeCompletionCore) SetIgnoredTokens(tokenTypes ...int) {
+	c.ignoredTokens = make(map[int]bool)
+
+	for _, t := range tokenTypes {
+		c.ignoredTokens[t] = true
+	}
+}
+
+// CollectCandidates returns the candidates at the token with index
+// caretTokenIndex in the parser's token stream. If context is nil the walk
+// starts at the first token with rule 0; otherwise it starts at the start
+// token of context with the rule of context, which saves walking the whole
+// input for large files.
+func (c *CodeCompletionCore) CollectCandidates(caretTokenIndex int, context ParserRuleContext) *CompletionCandidates {
+	c.candidates = &CompletionCandidates{
+		Tokens: make(map[int][]int),
+		Rules:  make(map[int][]int),
+	}
+	c.shortcuts = make(map[completionShortcut]map[int]bool)
+
+	tokenStartIndex := 0
+	startRule := 0
+	callStack := make([]int, 0)
+
+	if context != nil {
+		if context.GetStart() != nil {
+			tokenStartIndex = context.GetStart().GetTokenIndex()
+		}
+
+		startRule = context.GetRuleIndex()
+		callStack = c.getInvocationStack(context)
+	}
+
+	c.collectTokens(tokenStartIndex, caretTokenIndex)
+	c.processRule(c.atn.ruleToStartState[startRule], 0, callStack, 0)
+
+	return c.candidates
+}
+
+// collectTokens records the types of the default channel tokens from
+// tokenStartIndex up to the first one at or after the caret, leaving the
+// position of the token stream unchanged.
+func (c *CodeCompletionCore) collectTokens(tokenStartIndex, caretTokenIndex int) {
+	stream := c.parser.GetTokenStream()
+	// The index is -1 until the stream has been read from
+	currentIndex := intMax(stream.Index(), 0)
+
+	defer stream.Seek(currentIndex)
+
+	stream.Seek(tokenStartIndex)
+
+	c.tokens = make([]int, 0)
+
+	for offset := 1; ; offset++ {
+		token := stream.LT(offset)
+
+		if token.GetChannel() == TokenDefaultChannel {
+			c.tokens = append(c.tokens, token.GetTokenType())
+
+			if token.GetTokenIndex() >= caretTokenIndex {
+				break
+			}
+		}
+
+		if token.GetTokenType() == TokenEOF {
+			break
+		}
+	}
+}
+
+// getInvocationStack returns the rule indexes of the contexts enclosing
+// context, outermost first. It walks the same parent chain as
+// GetRuleInvocationStack.
+func (c *CodeCompletionCore) getInvocationStack(context ParserRuleContext) []int {
+	stack := make([]int, 0)
+
+	for p := context.GetParent(); p != nil; p = p.GetParent() {
+		prc, ok := p.(ParserRuleContext)
+
+		if !ok {
+			break
+		}
+
+		stack = append([]int{prc.GetRuleIndex()}, stack...)
+	}
+
+	return stack
+}
+
+// translateStackToRuleIndex adds a rule candidate for the outermost preferred
+// rule in ruleStack, if any, and reports whether there was one.
+func (c *CodeCompletionCore) translateStackToRuleIndex(ruleStack []int) bool {
+	if len(c.preferredRules) == 0 {
+		return false
+	}
+
+	for i, r := range ruleStack {
+		if c.preferredRules[r] {
+			if _, ok := c.candidates.Rules[r]; !ok {
+				path := make([]int, i)
+
+				copy(path, ruleStack[:i])
+				c.candidates.Rules[r] = path
+			}
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *CodeCompletionCore) addTokenCandidate(tokenType int, following []int) {
+	if c.ignoredTokens[tokenType] {
+		return
+	}
+
+	if existing, ok := c.candidates.Tokens[tokenType]; ok && !intSlicesEqual(existing, following) {
+		// Different paths disagree on what follows
+		c.candidates.Tokens[tokenType] = []int{}
+
+		return
+	}
+
+	c.candidates.Tokens[tokenType] = following
+}
+
+// getFollowingTokens returns the token types that must follow the single
+// token matched by t within the same rule: those matched by a chain of states
+// with a single transition each.
+func (c *CodeCompletionCore) getFollowingTokens(t Transition) []int {
+	result := make([]int, 0)
+	seen := make(map[int]bool)
+
+	for s := t.getTarget(); !seen[s.GetStateNumber()] && len(s.GetTransitions()) == 1; {
+		if _, ok := s.(*RuleStopState); ok {
+			break
+		}
+
+		seen[s.GetStateNumber()] = true
+		next := s.GetTransitions()[0]
+
+		switch tt := next.(type) {
+		case *AtomTransition:
+			if c.ignoredTokens[tt.label] {
+				return result
+			}
+
+			result = append(result, tt.label)
+
+		case *EpsilonTransition:
+			// Skip
+
+		default:
+			return result
+		}
+
+		s = next.getTarget()
+	}
+
+	return result
+}
+
+// getFollowSets returns the cached follow sets of the rule started by
+// startState: the token sets that can be matched first in the rule together
+// with the path of rules entered to reach them.
+func (c *CodeCompletionCore) getFollowSets(startState ATNState) *completionFollowSets {
+	if f, ok := c.followSets[startState.GetStateNumber()]; ok {
+		return f
+	}
+
+	f := &completionFollowSets{
+		sets:     make([]*completionFollowSet, 0),
+		combined: c.analyzer.Look(startState, nil, nil),
+	}
+
+	stopState := c.atn.ruleToStopState[startState.GetRuleIndex()]
+
+	c.collectFollowSets(startState, stopState, f, make([]int, 0), make([]int, 0))
+	c.followSets[startState.GetStateNumber()] = f
+
+	return f
+}
+
+// collectFollowSets adds the token sets reachable from s to f. stateStack
+// holds the state numbers on the current path only, so that a state reached
+// again through a different rule path is walked again for that path while
+// loops on the current path are cut.
+func (c *CodeCompletionCore) collectFollowSets(s, stopState ATNState, f *completionFollowSets, stateStack, ruleStack []int) {
+	if intSliceContains(stateStack, s.GetStateNumber()) {
+		return
+	}
+
+	if _, ok := s.(*RuleStopState); ok || s == stopState {
+		return
+	}
+
+	stateStack = append(stateStack, s.GetStateNumber())
+
+	for _, t := range s.GetTransitions() {
+		switch tt := t.(type) {
+		case *RuleTransition:
+			if intSliceContains(ruleStack, tt.getTarget().GetRuleIndex()) {
+				continue
+			}
+
+			c.collectFollowSets(tt.getTarget(), stopState, f, stateStack, append(ruleStack, tt.getTarget().GetRuleIndex()))
+
+		case *WildcardTransition:
+			set := NewIntervalSet()
+
+			set.addRange(TokenMinUserTokenType, c.atn.maxTokenType)
+			f.sets = append(f.sets, c.newFollowSet(set, ruleStack, nil))
+
+		default:
+			if t.getIsEpsilon() {
+				c.collectFollowSets(t.getTarget(), stopState, f, stateStack, ruleStack)
+
+				continue
+			}
+
+			set := t.getLabel()
+
+			if set == nil || set.length() == 0 {
+				continue
+			}
+
+			if _, ok := t.(*NotSetTransition); ok {
+				set = set.complement(TokenMinUserTokenType, c.atn.maxTokenType)
+			}
+
+			var following []int
+
+			if set.length() == 1 {
+				following = c.getFollowingTokens(t)
+			}
+
+			f.sets = append(f.sets, c.newFollowSet(set, ruleStack, following))
+		}
+	}
+}
+
+func (c *CodeCompletionCore) newFollowSet(set *IntervalSet, ruleStack, following []int) *completionFollowSet {
+	path := make([]int, len(ruleStack))
+
+	copy(path, ruleStack)
+
+	if following == nil {
+		following = []int{}
+	}
+
+	return &completionFollowSet{set: set, path: path, following: following}
+}
+
+// processRule walks the rule started by startState from token tokenIndex and
+// returns the token indexes at which the rule can end. Candidates are
+// collected whenever the walk reaches the caret.
+func (c *CodeCompletionCore) processRule(startState ATNState, tokenIndex int, callStack []int, precedence int) map[int]bool {
+	key := completionShortcut{startState.GetRuleIndex(), tokenIndex, precedence}
+
+	if result, ok := c.shortcuts[key]; ok {
+		return result
+	}
+
+	result := make(map[int]bool)
+	followSets := c.getFollowSets(startState)
+
+	callStack = append(callStack, startState.GetRuleIndex())
+
+	if tokenIndex >= len(c.tokens)-1 {
+		// At the caret: everything the rule can start with is a candidate
+		if c.preferredRules[startState.GetRuleIndex()] {
+			c.translateStackToRuleIndex(callStack)
+		} else {
+			for _, fs := range followSets.sets {
+				fullPath := append(append([]int{}, callStack...), fs.path...)
+
+				if c.translateStackToRuleIndex(fullPath) {
+					continue
+				}
+
+				for _, symbol := range fs.set.ToList() {
+					c.addTokenCandidate(symbol, fs.following)
+				}
+			}
+		}
+
+		// If the rule can be empty, what follows it is a candidate too
+		if followSets.combined.contains(TokenEpsilon) {
+			result[tokenIndex] = true
+		}
+
+		return result
+	}
+
+	// Only walk the rule if it can be passed without consuming anything or
+	// if it can match the current token.
+	currentSymbol := c.tokens[tokenIndex]
+
+	if !followSets.combined.contains(TokenEpsilon) && !followSets.combined.contains(currentSymbol) {
+		c.shortcuts[key] = result
+
+		return result
+	}
+
+	pipeline := []completionPipelineEntry{{startState, tokenIndex}}
+
+	for len(pipeline) > 0 {
+		entry := pipeline[len(pipeline)-1]
+		pipeline = pipeline[:len(pipeline)-1]
+
+		if _, ok := entry.state.(*RuleStopState); ok {
+			result[entry.tokenIndex] = true
+
+			continue
+		}
+
+		atCaret := entry.tokenIndex >= len(c.tokens)-1
+		currentSymbol = c.tokens[entry.tokenIndex]
+
+		for _, t := range entry.state.GetTransitions() {
+			switch tt := t.(type) {
+			case *RuleTransition:
+				endStatus := c.processRule(tt.getTarget(), entry.tokenIndex, callStack, tt.precedence)
+
+				for position := range endStatus {
+					pipeline = append(pipeline, completionPipelineEntry{tt.followState, position})
+				}
+
+			case *PrecedencePredicateTransition:
+				if tt.precedence >= precedence {
+					pipeline = append(pipeline, completionPipelineEntry{tt.getTarget(), entry.tokenIndex})
+				}
+
+			case *WildcardTransition:
+				if !atCaret {
+					pipeline = append(pipeline, completionPipelineEntry{tt.getTarget(), entry.tokenIndex + 1})
+				} else if !c.translateStackToRuleIndex(callStack) {
+					for symbol := TokenMinUserTokenType; symbol <= c.atn.maxTokenType; symbol++ {
+						c.addTokenCandidate(symbol, []int{})
+					}
+				}
+
+			default:
+				if t.getIsEpsilon() {
+					pipeline = append(pipeline, completionPipelineEntry{t.getTarget(), entry.tokenIndex})
+
+					continue
+				}
+
+				set := t.getLabel()
+
+				if set == nil || set.length() == 0 {
+					continue
+				}
+
+				if _, ok := t.(*NotSetTransition); ok {
+					set = set.complement(TokenMinUserTokenType, c.atn.maxTokenType)
+				}
+
+				if !atCaret {
+					if set.contains(currentSymbol) {
+						pipeline = append(pipeline, completionPipelineEntry{t.getTarget(), entry.tokenIndex + 1})
+					}
+				} else if !c.translateStackToRuleIndex(callStack) {
+					following := []int{}
+
+					if set.length() == 1 {
+						following = c.getFollowingTokens(t)
+					}
+
+					for _, symbol := range set.ToList() {
+						c.addTokenCandidate(symbol, following)
+					}
+				}
+			}
+		}
+	}
+
+	c.shortcuts[key] = result
+
+	return result
+}
+
+func intSliceContains(list []int, v int) bool {
+	for _, e := range list {
+		if e == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func intSlicesEqual(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+// newCalcCompletion returns a CodeCompletionCore over the tokens of text and
+// the index of the token at the caret, which is the first default channel
+// token at or after caretOffset.
+func newCalcCompletion(text string, caretOffset int) (*CodeCompletionCore, int) {
+	stream := NewCommonTokenStream(NewCalcLexer(NewInputStream(text)), TokenDefaultChannel)
+	stream.Fill()
+
+	caret := -1
+
+	for _, tok := range stream.GetAllTokens() {
+		if tok.GetChannel() == TokenDefaultChannel && (tok.GetStop() >= caretOffset || tok.GetTokenType() == TokenEOF) {
+			caret = tok.GetTokenIndex()
+
+			break
+		}
+	}
+
+	return NewCodeCompletionCore(NewCalcParser(stream)), caret
+}
+
+func tokenCandidates(c *CompletionCandidates) string {
+	types := make([]int, 0)
+
+	for tokenType := range c.Tokens {
+		types = append(types, tokenType)
+	}
+
+	sort.Ints(types)
+
+	names := make([]string, 0)
+
+	for _, tokenType := range types {
+		if tokenType == TokenEOF {
+			names = append(names, "EOF")
+		} else {
+			names = append(names, calcSymbolicNames[tokenType])
+		}
+	}
+
+	return fmt.Sprint(names)
+}
+
+func TestCodeCompletionTokens(t *testing.T) {
+	tests := []struct {
+		text  string
+		caret int
+		want  string
+	}{
+		{"", 0, "[EOF PRINT LPAREN LBRACE ID INT QUOTE]"},
+		{"x = ", 4, "[LPAREN ID INT QUOTE]"},
+		{"x = 1 ", 6, "[SEMI PLUS]"},
+		{"print 1 ", 8, "[SEMI COMMA PLUS]"},
+		{"{ x; ", 5, "[PRINT LPAREN LBRACE RBRACE ID INT QUOTE]"},
+		{"\"a\" ", 4, "[SEMI PLUS COLON QUOTE]"},
+	}
+
+	for _, test := range tests {
+		core, caret := newCalcCompletion(test.text, test.caret)
+
+		if got := tokenCandidates(core.CollectCandidates(caret, nil)); got != test.want {
+			t.Errorf("%q at %d: got %s, want %s", test.text, test.caret, got, test.want)
+		}
+	}
+}
+
+// TestCodeCompletionRuleReachedThroughTwoPaths checks a token that starts
+// rule str both directly in stat and through expr, atom and text. The
+// preferred rule text is only on the second path, so the first one must not
+// hide it.
+func TestCodeCompletionRuleReachedThroughTwoPaths(t *testing.T) {
+	tests := []struct {
+		text  string
+		caret int
+		path  string
+	}{
+		{"", 0, "[0 1 3 4]"},
+		{"x = 1; ", 7, "[0 1 3 4]"},
+		{"{ ", 2, "[0 1 2 1 3 4]"},
+	}
+
+	for _, test := range tests {
+		core, caret := newCalcCompletion(test.text, test.caret)
+
+		core.SetPreferredRules(CalcParserRULE_text)
+
+		candidates := core.CollectCandidates(caret, nil)
+		path, ok := candidates.Rules[CalcParserRULE_text]
+
+		if !ok {
+			t.Errorf("%q at %d: text is not a candidate", test.text, test.caret)
+		} else if got := fmt.Sprint(path); got != test.path {
+			t.Errorf("%q at %d: got path %s, want %s", test.text, test.caret, got, test.path)
+		}
+
+		// stat can still start with a str that is not a text
+		if _, ok := candidates.Tokens[CalcParserQUOTE]; !ok {
+			t.Errorf("%q at %d: QUOTE is not a candidate", test.text, test.caret)
+		}
+	}
+}
+
+func TestCodeCompletionFollowSetsKeepEveryPath(t *testing.T) {
+	core, _ := newCalcCompletion("", 0)
+	f := core.getFollowSets(core.atn.ruleToStartState[CalcParserRULE_stat])
+
+	paths := make(map[string]bool)
+
+	for _, fs := range f.sets {
+		if fs.set.contains(CalcParserQUOTE) {
+			paths[fmt.Sprint(fs.path)] = true
+		}
+	}
+
+	for _, want := range []string{"[6]", "[3 4 5 6]"} {
+		if !paths[want] {
+			t.Errorf("no follow set for QUOTE with path %s, got %v", want, paths)
+		}
+	}
+}
+
+func TestCodeCompletionWithContext(t *testing.T) {
+	text := "x = 1; print a, "
+	_, tree := parseCalc(text)
+	stat := tree.(*ProgContext).AllStat()[1]
+
+	core, caret := newCalcCompletion(text, len(text))
+
+	core.SetPreferredRules(CalcParserRULE_text)
+
+	candidates := core.CollectCandidates(caret, stat)
+
+	if got := tokenCandidates(candidates); got != "[LPAREN ID INT]" {
+		t.Errorf("got %s, want [LPAREN ID INT]", got)
+	}
+
+	if got := fmt.Sprint(candidates.Rules[CalcParserRULE_text]); got != "[0 1 3 4]" {
+		t.Errorf("got path %s, want [0 1 3 4]", got)
+	}
+}
@@ -44,11 +44,13 @@ type CommonTokenStream struct {
 	tokens []Token
 }
 
-func NewCommonTokenStream(lexer Lexer, channel int) *CommonTokenStream {
+// NewCommonTokenStream returns a stream of the tokens of source, usually a
+// Lexer, that reads the tokens of channel.
+func NewCommonTokenStream(source TokenSource, channel int) *CommonTokenStream {
 	return &CommonTokenStream{
 		channel:     channel,
 		index:       -1,
-		tokenSource: lexer,
+		tokenSource: source,
 		tokens:      make([]Token, 0),
 	}
 }
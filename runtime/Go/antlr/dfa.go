@@ -97,6 +97,34 @@ func (d *DFA) GetStates() map[string]*DFAState {
 	return d.states
 }
 
+// GetDecision returns the decision number d predicts.
+func (d *DFA) GetDecision() int {
+	return d.decision
+}
+
+// GetATNStartState returns the ATN decision state from which d was created.
+func (d *DFA) GetATNStartState() DecisionState {
+	return d.atnStartState
+}
+
+// IsPrecedenceDfa returns whether d is a precedence DFA. The start states of a
+// precedence DFA are stored per precedence level in the edges of s0 instead of
+// in s0 itself.
+func (d *DFA) IsPrecedenceDfa() bool {
+	return d.precedenceDfa
+}
+
+// GetS0 returns the start state of d, or nil if prediction has not yet run for
+// this decision.
+func (d *DFA) GetS0() *DFAState {
+	return d.s0
+}
+
+// GetSortedStates returns the states of d sorted by state number.
+func (d *DFA) GetSortedStates() []*DFAState {
+	return d.sortedStates()
+}
+
 type DFAStateList []*DFAState
 
 func (d DFAStateList) Len() int           { return len(d) }
@@ -23,6 +23,16 @@ func (p *PredPrediction) String() string {
 	return "(" + fmt.Sprint(p.pred) + ", " + fmt.Sprint(p.alt) + ")"
 }
 
+// GetAlt returns the alternative predicted when the predicate of p holds.
+func (p *PredPrediction) GetAlt() int {
+	return p.alt
+}
+
+// GetPred returns the predicate of p.
+func (p *PredPrediction) GetPred() SemanticContext {
+	return p.pred
+}
+
 // DFAState represents a set of possible ATN configurations. As Aho, Sethi,
 // Ullman p. 117 says: "The DFA uses its state to keep track of all possible
 // states the ATN can be in after reading each input symbol. That is to say,
@@ -107,6 +117,46 @@ func (d *DFAState) GetAltSet() *Set {
 	return alts
 }
 
+// GetStateNumber returns the number of d within its DFA.
+func (d *DFAState) GetStateNumber() int {
+	return d.stateNumber
+}
+
+// GetConfigs returns the ATN configurations d represents.
+func (d *DFAState) GetConfigs() ATNConfigSet {
+	return d.configs
+}
+
+// GetEdges returns the outgoing edges of d. Parser DFA edges are shifted up by
+// one so that Token.EOF maps to the first element; nil elements are edges that
+// have not been computed yet.
+func (d *DFAState) GetEdges() []*DFAState {
+	return d.edges
+}
+
+// IsAcceptState returns whether d is an accept state.
+func (d *DFAState) IsAcceptState() bool {
+	return d.isAcceptState
+}
+
+// GetPrediction returns the alternative (or lexer token type) predicted by d
+// when it is an accept state.
+func (d *DFAState) GetPrediction() int {
+	return d.prediction
+}
+
+// RequiresFullContext returns whether d was created by an SLL prediction that
+// found a conflict, so reaching it triggers full-context prediction.
+func (d *DFAState) RequiresFullContext() bool {
+	return d.requiresFullContext
+}
+
+// GetPredicates returns the predicate/alternative pairs that resolve d, or nil
+// if d is not predicate-resolved.
+func (d *DFAState) GetPredicates() []*PredPrediction {
+	return d.predicates
+}
+
 func (d *DFAState) setPrediction(v int) {
 	d.prediction = v
 }
@@ -0,0 +1,153 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+// DFAStats is a read-only snapshot of the DFA cached for a single decision.
+// DFAs grow while parsing, so the figures are only valid for the moment the
+// snapshot was taken.
+type DFAStats struct {
+	// Decision is the decision number of the DFA.
+	Decision int
+
+	// RuleIndex is the index of the rule containing the decision, or -1 if the
+	// decision does not belong to a rule (e.g. a lexer mode's Tokens decision).
+	RuleIndex int
+
+	// RuleName is the name of the rule containing the decision, or the empty
+	// string if it is not known.
+	RuleName string
+
+	// PrecedenceDfa is true if the DFA is for a precedence decision.
+	PrecedenceDfa bool
+
+	// NumStates is the number of DFA states, not counting the synthetic start
+	// state of a precedence DFA.
+	NumStates int
+
+	// NumEdges is the number of computed edges between DFA states. Edges to
+	// the error state are not counted.
+	NumEdges int
+
+	// NumAcceptStates is the number of accept states.
+	NumAcceptStates int
+
+	// NumFullContextStates is the number of states that trigger full-context
+	// prediction.
+	NumFullContextStates int
+
+	// PredicateStates is the state numbers, in ascending order, of the accept
+	// states whose prediction is resolved by semantic predicates.
+	PredicateStates []int
+}
+
+// NewDFAStats computes the statistics of dfa. ruleNames is used to resolve the
+// name of the rule containing the decision and may be nil.
+func NewDFAStats(dfa *DFA, ruleNames []string) *DFAStats {
+	s := &DFAStats{
+		Decision:        dfa.decision,
+		RuleIndex:       -1,
+		PrecedenceDfa:   dfa.precedenceDfa,
+		PredicateStates: make([]int, 0),
+	}
+
+	if dfa.atnStartState != nil {
+		s.RuleIndex = dfa.atnStartState.GetRuleIndex()
+
+		if s.RuleIndex >= 0 && s.RuleIndex < len(ruleNames) {
+			s.RuleName = ruleNames[s.RuleIndex]
+		}
+	}
+
+	for _, state := range dfa.sortedStates() {
+		s.NumStates++
+		s.NumEdges += countDFAEdges(state)
+
+		if state.isAcceptState {
+			s.NumAcceptStates++
+
+			if state.predicates != nil {
+				s.PredicateStates = append(s.PredicateStates, state.stateNumber)
+			}
+		}
+
+		if state.requiresFullContext {
+			s.NumFullContextStates++
+		}
+	}
+
+	return s
+}
+
+// countDFAEdges returns the number of edges leaving s that lead to a state other
+// than ATNSimulatorError.
+func countDFAEdges(s *DFAState) int {
+	n := 0
+
+	for _, t := range s.edges {
+		if t != nil && t != ATNSimulatorError {
+			n++
+		}
+	}
+
+	return n
+}
+
+// DFASummary aggregates the DFA statistics of every decision of a recognizer.
+// Generated recognizers share one decisionToDFA slice among all instances of a
+// grammar, so a summary reflects all parsing done with that grammar in the
+// current process.
+type DFASummary struct {
+	// Decisions holds the statistics of each decision, indexed by decision
+	// number.
+	Decisions []*DFAStats
+
+	// NumDecisions is the number of decisions with at least one DFA state.
+	NumDecisions int
+
+	NumStates            int
+	NumEdges             int
+	NumAcceptStates      int
+	NumFullContextStates int
+	NumPredicateStates   int
+}
+
+// NewDFASummary computes the statistics of every DFA in decisionToDFA and their
+// totals. ruleNames is used to resolve rule names and may be nil.
+func NewDFASummary(decisionToDFA []*DFA, ruleNames []string) *DFASummary {
+	s := &DFASummary{Decisions: make([]*DFAStats, len(decisionToDFA))}
+
+	for i, dfa := range decisionToDFA {
+		if dfa == nil {
+			continue
+		}
+
+		d := NewDFAStats(dfa, ruleNames)
+
+		s.Decisions[i] = d
+
+		if d.NumStates > 0 {
+			s.NumDecisions++
+		}
+
+		s.NumStates += d.NumStates
+		s.NumEdges += d.NumEdges
+		s.NumAcceptStates += d.NumAcceptStates
+		s.NumFullContextStates += d.NumFullContextStates
+		s.NumPredicateStates += len(d.PredicateStates)
+	}
+
+	return s
+}
+
+// GetDFASummary returns the DFA statistics of every decision of the parser.
+func (p *BaseParser) GetDFASummary() *DFASummary {
+	return NewDFASummary(p.Interpreter.decisionToDFA, p.GetRuleNames())
+}
+
+// GetDFASummary returns the DFA statistics of every lexer mode. Lexer DFAs are
+// indexed by mode rather than by rule, so RuleName is always empty.
+func (b *BaseLexer) GetDFASummary() *DFASummary {
+	return NewDFASummary(b.Interpreter.DecisionToDFA(), nil)
+}
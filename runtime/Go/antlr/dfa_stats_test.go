@@ -0,0 +1,116 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"testing"
+)
+
+func TestDFASummaryBeforeParsing(t *testing.T) {
+	p := NewCalcParser(nil)
+	s := p.GetDFASummary()
+
+	if len(s.Decisions) != len(calcParserATN.DecisionToState) {
+		t.Fatalf("got %d decisions, want %d", len(s.Decisions), len(calcParserATN.DecisionToState))
+	}
+
+	if s.NumDecisions != 0 || s.NumStates != 0 || s.NumEdges != 0 {
+		t.Errorf("got %d decisions, %d states and %d edges before parsing", s.NumDecisions, s.NumStates, s.NumEdges)
+	}
+
+	for i, dfa := range p.Interpreter.decisionToDFA {
+		if dfa.GetDecision() != i || dfa.GetATNStartState() != calcParserATN.DecisionToState[i] || dfa.GetS0() != nil {
+			t.Errorf("decision %d: got decision %d, start state %v, s0 %v", i, dfa.GetDecision(), dfa.GetATNStartState(), dfa.GetS0())
+		}
+	}
+}
+
+func TestDFASummaryAfterParsing(t *testing.T) {
+	p, _ := parseCalc(calcEditText)
+	s := p.GetDFASummary()
+
+	if s.NumDecisions == 0 {
+		t.Fatal("no decision has DFA states after parsing")
+	}
+
+	var decisions, states, edges, accepts int
+
+	for i, d := range s.Decisions {
+		dfa := p.Interpreter.decisionToDFA[i]
+		ruleIndex := calcParserATN.DecisionToState[i].GetRuleIndex()
+
+		if d.Decision != i || d.RuleIndex != ruleIndex || d.RuleName != calcRuleNames[ruleIndex] {
+			t.Errorf("decision %d: got decision %d in rule %d %q", i, d.Decision, d.RuleIndex, d.RuleName)
+		}
+
+		if d.PrecedenceDfa || d.NumFullContextStates != 0 || len(d.PredicateStates) != 0 {
+			t.Errorf("decision %d: Calc has no precedence, full context or predicate states", i)
+		}
+
+		// The figures match the states of the DFA
+		sorted := dfa.GetSortedStates()
+		n, a := 0, 0
+
+		for j, state := range sorted {
+			if j > 0 && state.GetStateNumber() <= sorted[j-1].GetStateNumber() {
+				t.Errorf("decision %d: states not sorted", i)
+			}
+
+			for _, e := range state.GetEdges() {
+				if e != nil && e != ATNSimulatorError {
+					n++
+				}
+			}
+
+			if state.IsAcceptState() {
+				a++
+
+				if state.GetPrediction() < 1 {
+					t.Errorf("decision %d: accept state %d predicts %d", i, state.GetStateNumber(), state.GetPrediction())
+				}
+			}
+		}
+
+		if d.NumStates != len(sorted) || d.NumEdges != n || d.NumAcceptStates != a {
+			t.Errorf("decision %d: got %d states, %d edges, %d accept states, want %d, %d, %d", i, d.NumStates, d.NumEdges, d.NumAcceptStates, len(sorted), n, a)
+		}
+
+		if d.NumStates > 0 {
+			decisions++
+
+			if dfa.GetS0() == nil {
+				t.Errorf("decision %d: states without s0", i)
+			}
+		}
+
+		states += d.NumStates
+		edges += d.NumEdges
+		accepts += d.NumAcceptStates
+	}
+
+	if s.NumDecisions != decisions || s.NumStates != states || s.NumEdges != edges || s.NumAcceptStates != accepts {
+		t.Errorf("got totals %d, %d, %d, %d, want %d, %d, %d, %d", s.NumDecisions, s.NumStates, s.NumEdges, s.NumAcceptStates, decisions, states, edges, accepts)
+	}
+}
+
+func TestLexerDFASummary(t *testing.T) {
+	lexer := NewCalcLexer(NewInputStream("x = \"s\" + 1;\n"))
+	stream := NewCommonTokenStream(lexer, TokenDefaultChannel)
+
+	stream.Fill()
+
+	s := lexer.GetDFASummary()
+
+	// Only the DFAs of the two modes are used, both by the string
+	if s.NumDecisions != 2 {
+		t.Fatalf("got %d decisions with states, want 2", s.NumDecisions)
+	}
+
+	for mode := range calcLexerModeNames {
+		if d := s.Decisions[mode]; d.RuleName != "" || d.NumAcceptStates == 0 || d.NumEdges == 0 {
+			t.Errorf("mode %d: got rule %q, %d accept states, %d edges", mode, d.RuleName, d.NumAcceptStates, d.NumEdges)
+		}
+	}
+}
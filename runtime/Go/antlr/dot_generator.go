@@ -0,0 +1,354 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DOTGenerator renders ATN rule subgraphs and decision DFAs in the Graphviz DOT
+// language. It is intended for debugging grammar decisions; the output can be
+// turned into an image with, for example, "dot -Tsvg".
+type DOTGenerator struct {
+	ruleNames     []string
+	literalNames  []string
+	symbolicNames []string
+}
+
+// NewDOTGenerator returns a DOTGenerator that labels rules and tokens using the
+// given names. Any of the name slices may be nil, in which case numbers are
+// used instead.
+func NewDOTGenerator(ruleNames, literalNames, symbolicNames []string) *DOTGenerator {
+	return &DOTGenerator{
+		ruleNames:     ruleNames,
+		literalNames:  literalNames,
+		symbolicNames: symbolicNames,
+	}
+}
+
+// NewDOTGeneratorForRecognizer returns a DOTGenerator that takes its names from
+// recog.
+func NewDOTGeneratorForRecognizer(recog Recognizer) *DOTGenerator {
+	return NewDOTGenerator(recog.GetRuleNames(), recog.GetLiteralNames(), recog.GetSymbolicNames())
+}
+
+// GetRuleDOT returns the DOT graph of the ATN states reachable from the start
+// state of rule ruleIndex without entering other rules. Invocations of other
+// rules are drawn as a dashed edge to the follow state, labelled with the name
+// of the invoked rule.
+func (d *DOTGenerator) GetRuleDOT(atn *ATN, ruleIndex int) string {
+	if ruleIndex < 0 || ruleIndex >= len(atn.ruleToStartState) {
+		panic("Invalid rule index " + strconv.Itoa(ruleIndex) + ".")
+	}
+
+	isLexer := atn.grammarType == ATNTypeLexer
+	start := atn.ruleToStartState[ruleIndex]
+
+	// Collect the states of the rule, staying out of invoked rules.
+	visited := make(map[int]ATNState)
+	work := []ATNState{start}
+
+	for len(work) > 0 {
+		s := work[len(work)-1]
+		work = work[:len(work)-1]
+
+		if _, ok := visited[s.GetStateNumber()]; ok {
+			continue
+		}
+
+		visited[s.GetStateNumber()] = s
+
+		if _, ok := s.(*RuleStopState); ok {
+			continue
+		}
+
+		for _, t := range s.GetTransitions() {
+			if rt, ok := t.(*RuleTransition); ok {
+				work = append(work, rt.followState)
+			} else {
+				work = append(work, t.getTarget())
+			}
+		}
+	}
+
+	numbers := make([]int, 0, len(visited))
+
+	for n := range visited {
+		numbers = append(numbers, n)
+	}
+
+	sort.Ints(numbers)
+
+	var buf bytes.Buffer
+
+	buf.WriteString("digraph ATN {\n")
+	buf.WriteString("rankdir=LR;\n")
+	buf.WriteString("label=" + dotQuote(d.ruleName(ruleIndex)) + ";\n")
+
+	for _, n := range numbers {
+		buf.WriteString(d.getATNStateNode(visited[n]))
+	}
+
+	for _, n := range numbers {
+		s := visited[n]
+
+		if _, ok := s.(*RuleStopState); ok {
+			continue
+		}
+
+		for i, t := range s.GetTransitions() {
+			buf.WriteString(d.getATNEdge(atn, s, t, i, isLexer))
+		}
+	}
+
+	buf.WriteString("}\n")
+
+	return buf.String()
+}
+
+func (d *DOTGenerator) getATNStateNode(s ATNState) string {
+	shape := "circle"
+	label := strconv.Itoa(s.GetStateNumber())
+
+	switch st := s.(type) {
+	case *RuleStopState:
+		shape = "doublecircle"
+	case *RuleStartState:
+		if st.isPrecedenceRule {
+			label += "\nprecedence"
+		}
+	}
+
+	if ds, ok := s.(DecisionState); ok && ds.getDecision() >= 0 {
+		shape = "box"
+		label += "\nd=" + strconv.Itoa(ds.getDecision())
+
+		if ds.getNonGreedy() {
+			label += " nongreedy"
+		}
+	}
+
+	stateType := "INVALID"
+
+	if t := s.GetStateType(); t >= 0 && t < len(ATNStateSerializationNames) {
+		stateType = ATNStateSerializationNames[t]
+	}
+
+	return fmt.Sprintf("s%d [fontsize=11, shape=%s, label=%s, tooltip=%s];\n",
+		s.GetStateNumber(), shape, dotQuote(label), dotQuote(stateType))
+}
+
+func (d *DOTGenerator) getATNEdge(atn *ATN, s ATNState, t Transition, i int, isLexer bool) string {
+	var label, style string
+
+	target := t.getTarget()
+
+	switch tt := t.(type) {
+	case *RuleTransition:
+		target = tt.followState
+		label = d.ruleName(tt.ruleIndex)
+		style = "dashed"
+
+		if tt.precedence > 0 {
+			label += "[" + strconv.Itoa(tt.precedence) + "]"
+		}
+	case *EpsilonTransition:
+		label = "ε"
+
+		if tt.outermostPrecedenceReturn >= 0 {
+			label += " <" + d.ruleName(tt.outermostPrecedenceReturn) + ">"
+		}
+	case *PredicateTransition:
+		label = "{" + d.ruleName(tt.ruleIndex) + ":" + strconv.Itoa(tt.predIndex) + "}?"
+		style = "dotted"
+	case *PrecedencePredicateTransition:
+		label = "{" + strconv.Itoa(tt.precedence) + " >= _p}?"
+		style = "dotted"
+	case *ActionTransition:
+		if isLexer && tt.actionIndex >= 0 && tt.actionIndex < len(atn.lexerActions) {
+			label = "{" + d.getLexerActionLabel(atn.lexerActions[tt.actionIndex]) + "}"
+		} else {
+			label = "{" + d.ruleName(tt.ruleIndex) + ":" + strconv.Itoa(tt.actionIndex) + "}"
+		}
+		style = "dotted"
+	case *AtomTransition:
+		label = d.getSymbolLabel(tt.label, isLexer)
+	case *RangeTransition:
+		label = d.getSymbolLabel(tt.start, isLexer) + ".." + d.getSymbolLabel(tt.stop, isLexer)
+	case *NotSetTransition:
+		label = "~" + d.getSetLabel(tt.intervalSet, isLexer)
+	case *SetTransition:
+		label = d.getSetLabel(tt.intervalSet, isLexer)
+	case *WildcardTransition:
+		label = "."
+	default:
+		label = TransitionserializationNames[t.getSerializationType()]
+	}
+
+	attrs := "fontsize=11, label=" + dotQuote(label)
+
+	if style != "" {
+		attrs += ", style=" + style
+	}
+
+	if len(s.GetTransitions()) > 1 {
+		attrs += ", taillabel=" + dotQuote(strconv.Itoa(i+1))
+	}
+
+	return fmt.Sprintf("s%d -> s%d [%s];\n", s.GetStateNumber(), target.GetStateNumber(), attrs)
+}
+
+// getLexerActionLabel returns the command of a lexer action, or the rule and
+// index of a custom action, which has no String method.
+func (d *DOTGenerator) getLexerActionLabel(action LexerAction) string {
+	if a, ok := action.(*LexerCustomAction); ok {
+		return d.ruleName(a.ruleIndex) + ":" + strconv.Itoa(a.actionIndex)
+	}
+
+	return fmt.Sprint(action)
+}
+
+func (d *DOTGenerator) getSetLabel(set *IntervalSet, isLexer bool) string {
+	if set == nil || len(set.intervals) == 0 {
+		return "{}"
+	}
+
+	elems := make([]string, 0, len(set.intervals))
+
+	for _, v := range set.intervals {
+		if v.stop == v.start+1 {
+			elems = append(elems, d.getSymbolLabel(v.start, isLexer))
+		} else {
+			elems = append(elems, d.getSymbolLabel(v.start, isLexer)+".."+d.getSymbolLabel(v.stop-1, isLexer))
+		}
+	}
+
+	if len(elems) == 1 {
+		return elems[0]
+	}
+
+	return "{" + strings.Join(elems, ", ") + "}"
+}
+
+// getSymbolLabel returns the display name of token type t, or the quoted
+// character t for lexer ATNs.
+func (d *DOTGenerator) getSymbolLabel(t int, isLexer bool) string {
+	if t == TokenEOF {
+		return "EOF"
+	}
+
+	if isLexer {
+		return "'" + EscapeWhitespace(string(rune(t)), false) + "'"
+	}
+
+	if t >= 0 && t < len(d.literalNames) && d.literalNames[t] != "" {
+		return d.literalNames[t]
+	}
+
+	if t >= 0 && t < len(d.symbolicNames) && d.symbolicNames[t] != "" {
+		return d.symbolicNames[t]
+	}
+
+	return strconv.Itoa(t)
+}
+
+func (d *DOTGenerator) ruleName(ruleIndex int) string {
+	if ruleIndex >= 0 && ruleIndex < len(d.ruleNames) {
+		return d.ruleNames[ruleIndex]
+	}
+
+	return "rule" + strconv.Itoa(ruleIndex)
+}
+
+// GetDFADOT returns the DOT graph of dfa. Each state is labelled with its
+// number as printed by DFASerializer, the ATN state and alternative of each of
+// its configurations, and its prediction if it is an accept state. The start
+// states of a precedence DFA hang off a synthetic "precedence" node.
+func (d *DOTGenerator) GetDFADOT(dfa *DFA) string {
+	var buf bytes.Buffer
+
+	isLexer := false
+
+	if dfa.atnStartState != nil && dfa.atnStartState.GetATN() != nil {
+		isLexer = dfa.atnStartState.GetATN().grammarType == ATNTypeLexer
+	}
+
+	serializer := NewDFASerializer(dfa, d.literalNames, d.symbolicNames)
+	edgeLabel := serializer.getEdgeLabel
+
+	if isLexer {
+		edgeLabel = func(i int) string {
+			return d.getSymbolLabel(i+LexerATNSimulatorMinDFAEdge, true)
+		}
+	}
+
+	buf.WriteString("digraph DFA {\n")
+	buf.WriteString("rankdir=LR;\n")
+	buf.WriteString("label=" + dotQuote("decision "+strconv.Itoa(dfa.decision)) + ";\n")
+
+	states := dfa.sortedStates()
+
+	for _, s := range states {
+		shape := "circle"
+
+		if s.isAcceptState {
+			shape = "doublecircle"
+		}
+
+		buf.WriteString(fmt.Sprintf("s%d [fontsize=11, shape=%s, label=%s];\n",
+			s.stateNumber, shape, dotQuote(d.getDFAStateLabel(serializer, s))))
+	}
+
+	if dfa.precedenceDfa && dfa.s0 != nil {
+		buf.WriteString("precedence [fontsize=11, shape=point];\n")
+
+		for p, t := range dfa.s0.edges {
+			if t != nil {
+				buf.WriteString(fmt.Sprintf("precedence -> s%d [fontsize=11, label=%s];\n",
+					t.stateNumber, dotQuote("p="+strconv.Itoa(p))))
+			}
+		}
+	}
+
+	for _, s := range states {
+		for i, t := range s.edges {
+			if t == nil || t == ATNSimulatorError {
+				continue
+			}
+
+			buf.WriteString(fmt.Sprintf("s%d -> s%d [fontsize=11, label=%s];\n",
+				s.stateNumber, t.stateNumber, dotQuote(edgeLabel(i))))
+		}
+	}
+
+	buf.WriteString("}\n")
+
+	return buf.String()
+}
+
+func (d *DOTGenerator) getDFAStateLabel(serializer *DFASerializer, s *DFAState) string {
+	lines := []string{serializer.GetStateString(s)}
+
+	if s.configs != nil {
+		for _, c := range s.configs.GetItems() {
+			lines = append(lines, strconv.Itoa(c.GetState().GetStateNumber())+"|"+strconv.Itoa(c.GetAlt()))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// dotQuote returns s as a quoted DOT string.
+func dotQuote(s string) string {
+	s = strings.Replace(s, "\\", "\\\\", -1)
+	s = strings.Replace(s, "\"", "\\\"", -1)
+	s = strings.Replace(s, "\n", "\\n", -1)
+
+	return "\"" + s + "\""
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDOTGeneratorRule(t *testing.T) {
+	// block : '{' stat* '}' ;
+	want := `digraph ATN {
+rankdir=LR;
+label="block";
+s4 [fontsize=11, shape=circle, label="4", tooltip="RULE_START"];
+s5 [fontsize=11, shape=doublecircle, label="5", tooltip="RULE_STOP"];
+s60 [fontsize=11, shape=circle, label="60", tooltip="BASIC"];
+s61 [fontsize=11, shape=circle, label="61", tooltip="BASIC"];
+s62 [fontsize=11, shape=circle, label="62", tooltip="BASIC"];
+s63 [fontsize=11, shape=circle, label="63", tooltip="BASIC"];
+s64 [fontsize=11, shape=box, label="64\nd=6", tooltip="STAR_LOOP_ENTRY"];
+s65 [fontsize=11, shape=box, label="65\nd=5", tooltip="STAR_BLOCK_START"];
+s66 [fontsize=11, shape=circle, label="66", tooltip="BLOCK_END"];
+s67 [fontsize=11, shape=circle, label="67", tooltip="STAR_LOOP_BACK"];
+s68 [fontsize=11, shape=circle, label="68", tooltip="LOOP_END"];
+s69 [fontsize=11, shape=circle, label="69", tooltip="BASIC"];
+s70 [fontsize=11, shape=circle, label="70", tooltip="BASIC"];
+s4 -> s60 [fontsize=11, label="ε"];
+s60 -> s61 [fontsize=11, label="'{'"];
+s61 -> s64 [fontsize=11, label="ε"];
+s62 -> s63 [fontsize=11, label="stat", style=dashed];
+s63 -> s66 [fontsize=11, label="ε"];
+s64 -> s65 [fontsize=11, label="ε", taillabel="1"];
+s64 -> s68 [fontsize=11, label="ε", taillabel="2"];
+s65 -> s62 [fontsize=11, label="ε"];
+s66 -> s67 [fontsize=11, label="ε"];
+s67 -> s64 [fontsize=11, label="ε"];
+s68 -> s69 [fontsize=11, label="ε"];
+s69 -> s70 [fontsize=11, label="'}'"];
+s70 -> s5 [fontsize=11, label="ε"];
+}
+`
+
+	g := NewDOTGeneratorForRecognizer(NewCalcParser(nil))
+
+	if got := g.GetRuleDOT(calcParserATN, CalcParserRULE_block); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDOTGeneratorLexerRule(t *testing.T) {
+	// INCLUDE : '#include' ' '+ [a-z]+ {include(name)} -> channel(HIDDEN) ;
+	g := NewDOTGeneratorForRecognizer(NewCalcLexer(nil))
+	got := g.GetRuleDOT(calcLexerATN, CalcLexerINCLUDE-1)
+
+	for _, want := range []string{
+		`label="INCLUDE";`,
+		`s76 -> s77 [fontsize=11, label="'#'"];`,
+		`s91 -> s92 [fontsize=11, label="'a'..'z'"];`,
+		`s97 -> s98 [fontsize=11, label="{INCLUDE:0}", style=dotted];`,
+		`s99 -> s100 [fontsize=11, label="{channel(1)}", style=dotted];`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%s not in\n%s", want, got)
+		}
+	}
+}
+
+func TestDOTGeneratorInvalidRule(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic")
+		}
+	}()
+
+	NewDOTGenerator(nil, nil, nil).GetRuleDOT(calcParserATN, len(calcRuleNames))
+}
+
+func TestDOTGeneratorDFA(t *testing.T) {
+	p, _ := parseCalc(calcEditText)
+	g := NewDOTGeneratorForRecognizer(p)
+	drawn := 0
+
+	for _, dfa := range p.Interpreter.decisionToDFA {
+		states := dfa.GetSortedStates()
+
+		if len(states) == 0 {
+			continue
+		}
+
+		drawn++
+
+		got := g.GetDFADOT(dfa)
+
+		if !strings.HasPrefix(got, fmt.Sprintf("digraph DFA {\nrankdir=LR;\nlabel=\"decision %d\";\n", dfa.GetDecision())) {
+			t.Errorf("decision %d: got\n%s", dfa.GetDecision(), got)
+		}
+
+		// A node for each state and an edge for each computed edge
+		edges := 0
+
+		for _, s := range states {
+			shape := "circle"
+
+			if s.IsAcceptState() {
+				shape = "doublecircle"
+			}
+
+			node := fmt.Sprintf("s%d [fontsize=11, shape=%s, label=\"%s", s.GetStateNumber(), shape, NewDFASerializer(dfa, calcLiteralNames, calcSymbolicNames).GetStateString(s))
+
+			if !strings.Contains(got, node) {
+				t.Errorf("decision %d: %s not in\n%s", dfa.GetDecision(), node, got)
+			}
+
+			for _, e := range s.GetEdges() {
+				if e != nil && e != ATNSimulatorError {
+					edges++
+				}
+			}
+		}
+
+		if n := strings.Count(got, " -> "); n != edges {
+			t.Errorf("decision %d: got %d edges, want %d", dfa.GetDecision(), n, edges)
+		}
+	}
+
+	if drawn == 0 {
+		t.Error("no DFA drawn")
+	}
+}
@@ -11,26 +11,73 @@ import (
 	"strings"
 )
 
+// ErrorStrategy is the interface through which a parser reports and recovers
+// from syntax errors. Set it with Parser.SetErrorHandler.
 type ErrorStrategy interface {
-	reset(Parser)
+	// Reset is called when the parser is reset, to leave error recovery mode.
+	Reset(Parser)
+
+	// RecoverInline is called when the current token does not match the
+	// expected one. It returns the token to use as the match, or panics with
+	// a RecognitionException if it cannot recover.
 	RecoverInline(Parser) Token
+
+	// Recover is called after ReportError to resynchronize the parser with
+	// the input once a rule has failed.
 	Recover(Parser, RecognitionException)
+
+	// Sync is called before subrules and loop iterations to recover from
+	// errors early, before a rule fails.
 	Sync(Parser)
-	inErrorRecoveryMode(Parser) bool
+
+	// InErrorRecoveryMode returns true if the parser is recovering from an
+	// error, in which case no further errors are reported.
+	InErrorRecoveryMode(Parser) bool
+
+	// ReportError is called to report a RecognitionException.
 	ReportError(Parser, RecognitionException)
+
+	// ReportMatch is called each time the parser matches a token.
 	ReportMatch(Parser)
 }
 
+// ErrorStrategyHooks is an ErrorStrategy together with the steps
+// DefaultErrorStrategy is made of. A type that embeds DefaultErrorStrategy and
+// sets its Virt field to itself can override any of these, and
+// DefaultErrorStrategy calls the override.
+type ErrorStrategyHooks interface {
+	ErrorStrategy
+
+	BeginErrorCondition(Parser)
+	EndErrorCondition(Parser)
+
+	ReportNoViableAlternative(Parser, *NoViableAltException)
+	ReportInputMisMatch(Parser, *InputMisMatchException)
+	ReportFailedPredicate(Parser, *FailedPredicateException)
+	ReportUnwantedToken(Parser)
+	ReportMissingToken(Parser)
+
+	SingleTokenInsertion(Parser) bool
+	SingleTokenDeletion(Parser) Token
+	GetMissingSymbol(Parser) Token
+	GetExpectedTokens(Parser) *IntervalSet
+	GetTokenErrorDisplay(Token) string
+	GetErrorRecoverySet(Parser) *IntervalSet
+	ConsumeUntil(Parser, *IntervalSet)
+}
+
 // This is the default implementation of {@link ANTLRErrorStrategy} used for
 // error Reporting and recovery in ANTLR parsers.
 //
 type DefaultErrorStrategy struct {
+	Virt ErrorStrategyHooks // The most derived error strategy. Allows virtual method calls.
+
 	errorRecoveryMode bool
 	lastErrorIndex    int
 	lastErrorStates   *IntervalSet
 }
 
-var _ ErrorStrategy = &DefaultErrorStrategy{}
+var _ ErrorStrategyHooks = &DefaultErrorStrategy{}
 
 func NewDefaultErrorStrategy() *DefaultErrorStrategy {
 
@@ -40,7 +87,7 @@ func NewDefaultErrorStrategy() *DefaultErrorStrategy {
 	// error". This is used to suppress Reporting multiple error messages while
 	// attempting to recover from a detected syntax error.
 	//
-	// @see //inErrorRecoveryMode
+	// @see //InErrorRecoveryMode
 	//
 	d.errorRecoveryMode = false
 
@@ -55,10 +102,20 @@ func NewDefaultErrorStrategy() *DefaultErrorStrategy {
 	return d
 }
 
-// <p>The default implementation simply calls {@link //endErrorCondition} to
+// hooks returns the error strategy whose hooks d calls: Virt if it is set,
+// otherwise d itself.
+func (d *DefaultErrorStrategy) hooks() ErrorStrategyHooks {
+	if d.Virt != nil {
+		return d.Virt
+	}
+
+	return d
+}
+
+// <p>The default implementation simply calls {@link //EndErrorCondition} to
 // ensure that the handler is not in error recovery mode.</p>
-func (d *DefaultErrorStrategy) reset(recognizer Parser) {
-	d.endErrorCondition(recognizer)
+func (d *DefaultErrorStrategy) Reset(recognizer Parser) {
+	d.hooks().EndErrorCondition(recognizer)
 }
 
 //
@@ -67,11 +124,11 @@ func (d *DefaultErrorStrategy) reset(recognizer Parser) {
 //
 // @param recognizer the parser instance
 //
-func (d *DefaultErrorStrategy) beginErrorCondition(recognizer Parser) {
+func (d *DefaultErrorStrategy) BeginErrorCondition(recognizer Parser) {
 	d.errorRecoveryMode = true
 }
 
-func (d *DefaultErrorStrategy) inErrorRecoveryMode(recognizer Parser) bool {
+func (d *DefaultErrorStrategy) InErrorRecoveryMode(recognizer Parser) bool {
 	return d.errorRecoveryMode
 }
 
@@ -81,7 +138,7 @@ func (d *DefaultErrorStrategy) inErrorRecoveryMode(recognizer Parser) bool {
 //
 // @param recognizer
 //
-func (d *DefaultErrorStrategy) endErrorCondition(recognizer Parser) {
+func (d *DefaultErrorStrategy) EndErrorCondition(recognizer Parser) {
 	d.errorRecoveryMode = false
 	d.lastErrorStates = nil
 	d.lastErrorIndex = -1
@@ -90,17 +147,17 @@ func (d *DefaultErrorStrategy) endErrorCondition(recognizer Parser) {
 //
 // {@inheritDoc}
 //
-// <p>The default implementation simply calls {@link //endErrorCondition}.</p>
+// <p>The default implementation simply calls {@link //EndErrorCondition}.</p>
 //
 func (d *DefaultErrorStrategy) ReportMatch(recognizer Parser) {
-	d.endErrorCondition(recognizer)
+	d.hooks().EndErrorCondition(recognizer)
 }
 
 //
 // {@inheritDoc}
 //
 // <p>The default implementation returns immediately if the handler is already
-// in error recovery mode. Otherwise, it calls {@link //beginErrorCondition}
+// in error recovery mode. Otherwise, it calls {@link //BeginErrorCondition}
 // and dispatches the Reporting task based on the runtime type of {@code e}
 // according to the following table.</p>
 //
@@ -118,10 +175,10 @@ func (d *DefaultErrorStrategy) ReportMatch(recognizer Parser) {
 func (d *DefaultErrorStrategy) ReportError(recognizer Parser, e RecognitionException) {
 	// if we've already Reported an error and have not Matched a token
 	// yet successfully, don't Report any errors.
-	if d.inErrorRecoveryMode(recognizer) {
+	if d.hooks().InErrorRecoveryMode(recognizer) {
 		return // don't Report spurious errors
 	}
-	d.beginErrorCondition(recognizer)
+	d.hooks().BeginErrorCondition(recognizer)
 
 	switch t := e.(type) {
 	default:
@@ -129,11 +186,11 @@ func (d *DefaultErrorStrategy) ReportError(recognizer Parser, e RecognitionExcep
 		//            fmt.Println(e.stack)
 		recognizer.NotifyErrorListeners(e.GetMessage(), e.GetOffendingToken(), e)
 	case *NoViableAltException:
-		d.ReportNoViableAlternative(recognizer, t)
+		d.hooks().ReportNoViableAlternative(recognizer, t)
 	case *InputMisMatchException:
-		d.ReportInputMisMatch(recognizer, t)
+		d.hooks().ReportInputMisMatch(recognizer, t)
 	case *FailedPredicateException:
-		d.ReportFailedPredicate(recognizer, t)
+		d.hooks().ReportFailedPredicate(recognizer, t)
 	}
 }
 
@@ -158,8 +215,8 @@ func (d *DefaultErrorStrategy) Recover(recognizer Parser, e RecognitionException
 		d.lastErrorStates = NewIntervalSet()
 	}
 	d.lastErrorStates.addOne(recognizer.GetState())
-	followSet := d.getErrorRecoverySet(recognizer)
-	d.consumeUntil(recognizer, followSet)
+	followSet := d.hooks().GetErrorRecoverySet(recognizer)
+	d.hooks().ConsumeUntil(recognizer, followSet)
 }
 
 // The default implementation of {@link ANTLRErrorStrategy//Sync} makes sure
@@ -209,7 +266,7 @@ func (d *DefaultErrorStrategy) Recover(recognizer Parser, e RecognitionException
 //
 func (d *DefaultErrorStrategy) Sync(recognizer Parser) {
 	// If already recovering, don't try to Sync
-	if d.inErrorRecoveryMode(recognizer) {
+	if d.hooks().InErrorRecoveryMode(recognizer) {
 		return
 	}
 
@@ -225,16 +282,16 @@ func (d *DefaultErrorStrategy) Sync(recognizer Parser) {
 	switch s.GetStateType() {
 	case ATNStateBlockStart, ATNStateStarBlockStart, ATNStatePlusBlockStart, ATNStateStarLoopEntry:
 		// Report error and recover if possible
-		if d.SingleTokenDeletion(recognizer) != nil {
+		if d.hooks().SingleTokenDeletion(recognizer) != nil {
 			return
 		}
 		panic(NewInputMisMatchException(recognizer))
 	case ATNStatePlusLoopBack, ATNStateStarLoopBack:
-		d.ReportUnwantedToken(recognizer)
+		d.hooks().ReportUnwantedToken(recognizer)
 		expecting := NewIntervalSet()
 		expecting.addSet(recognizer.GetExpectedTokens())
-		whatFollowsLoopIterationOrRule := expecting.addSet(d.getErrorRecoverySet(recognizer))
-		d.consumeUntil(recognizer, whatFollowsLoopIterationOrRule)
+		whatFollowsLoopIterationOrRule := expecting.addSet(d.hooks().GetErrorRecoverySet(recognizer))
+		d.hooks().ConsumeUntil(recognizer, whatFollowsLoopIterationOrRule)
 	default:
 		// do nothing if we can't identify the exact kind of ATN state
 	}
@@ -274,7 +331,7 @@ func (d *DefaultErrorStrategy) ReportNoViableAlternative(recognizer Parser, e *N
 // @param e the recognition exception
 //
 func (this *DefaultErrorStrategy) ReportInputMisMatch(recognizer Parser, e *InputMisMatchException) {
-	msg := "mismatched input " + this.GetTokenErrorDisplay(e.offendingToken) +
+	msg := "mismatched input " + this.hooks().GetTokenErrorDisplay(e.offendingToken) +
 		" expecting " + e.getExpectedTokens().StringVerbose(recognizer.GetLiteralNames(), recognizer.GetSymbolicNames(), false)
 	recognizer.NotifyErrorListeners(msg, e.offendingToken, e)
 }
@@ -305,20 +362,20 @@ func (d *DefaultErrorStrategy) ReportFailedPredicate(recognizer Parser, e *Faile
 // input error.</p>
 //
 // <p>The default implementation simply returns if the handler is already in
-// error recovery mode. Otherwise, it calls {@link //beginErrorCondition} to
+// error recovery mode. Otherwise, it calls {@link //BeginErrorCondition} to
 // enter error recovery mode, followed by calling
 // {@link Parser//NotifyErrorListeners}.</p>
 //
 // @param recognizer the parser instance
 //
 func (d *DefaultErrorStrategy) ReportUnwantedToken(recognizer Parser) {
-	if d.inErrorRecoveryMode(recognizer) {
+	if d.hooks().InErrorRecoveryMode(recognizer) {
 		return
 	}
-	d.beginErrorCondition(recognizer)
+	d.hooks().BeginErrorCondition(recognizer)
 	t := recognizer.GetCurrentToken()
-	tokenName := d.GetTokenErrorDisplay(t)
-	expecting := d.GetExpectedTokens(recognizer)
+	tokenName := d.hooks().GetTokenErrorDisplay(t)
+	expecting := d.hooks().GetExpectedTokens(recognizer)
 	msg := "extraneous input " + tokenName + " expecting " +
 		expecting.StringVerbose(recognizer.GetLiteralNames(), recognizer.GetSymbolicNames(), false)
 	recognizer.NotifyErrorListeners(msg, t, nil)
@@ -334,21 +391,21 @@ func (d *DefaultErrorStrategy) ReportUnwantedToken(recognizer Parser) {
 // input error.</p>
 //
 // <p>The default implementation simply returns if the handler is already in
-// error recovery mode. Otherwise, it calls {@link //beginErrorCondition} to
+// error recovery mode. Otherwise, it calls {@link //BeginErrorCondition} to
 // enter error recovery mode, followed by calling
 // {@link Parser//NotifyErrorListeners}.</p>
 //
 // @param recognizer the parser instance
 //
 func (d *DefaultErrorStrategy) ReportMissingToken(recognizer Parser) {
-	if d.inErrorRecoveryMode(recognizer) {
+	if d.hooks().InErrorRecoveryMode(recognizer) {
 		return
 	}
-	d.beginErrorCondition(recognizer)
+	d.hooks().BeginErrorCondition(recognizer)
 	t := recognizer.GetCurrentToken()
-	expecting := d.GetExpectedTokens(recognizer)
+	expecting := d.hooks().GetExpectedTokens(recognizer)
 	msg := "missing " + expecting.StringVerbose(recognizer.GetLiteralNames(), recognizer.GetSymbolicNames(), false) +
-		" at " + d.GetTokenErrorDisplay(t)
+		" at " + d.hooks().GetTokenErrorDisplay(t)
 	recognizer.NotifyErrorListeners(msg, t, nil)
 }
 
@@ -403,7 +460,7 @@ func (d *DefaultErrorStrategy) ReportMissingToken(recognizer Parser) {
 //
 func (d *DefaultErrorStrategy) RecoverInline(recognizer Parser) Token {
 	// SINGLE TOKEN DELETION
-	MatchedSymbol := d.SingleTokenDeletion(recognizer)
+	MatchedSymbol := d.hooks().SingleTokenDeletion(recognizer)
 	if MatchedSymbol != nil {
 		// we have deleted the extra token.
 		// now, move past ttype token as if all were ok
@@ -411,8 +468,8 @@ func (d *DefaultErrorStrategy) RecoverInline(recognizer Parser) Token {
 		return MatchedSymbol
 	}
 	// SINGLE TOKEN INSERTION
-	if d.SingleTokenInsertion(recognizer) {
-		return d.GetMissingSymbol(recognizer)
+	if d.hooks().SingleTokenInsertion(recognizer) {
+		return d.hooks().GetMissingSymbol(recognizer)
 	}
 	// even that didn't work must panic the exception
 	panic(NewInputMisMatchException(recognizer))
@@ -445,7 +502,7 @@ func (d *DefaultErrorStrategy) SingleTokenInsertion(recognizer Parser) bool {
 	next := currentState.GetTransitions()[0].getTarget()
 	expectingAtLL2 := atn.NextTokens(next, recognizer.GetParserRuleContext())
 	if expectingAtLL2.contains(currentSymbolType) {
-		d.ReportMissingToken(recognizer)
+		d.hooks().ReportMissingToken(recognizer)
 		return true
 	}
 
@@ -472,9 +529,9 @@ func (d *DefaultErrorStrategy) SingleTokenInsertion(recognizer Parser) bool {
 //
 func (d *DefaultErrorStrategy) SingleTokenDeletion(recognizer Parser) Token {
 	NextTokenType := recognizer.GetTokenStream().LA(2)
-	expecting := d.GetExpectedTokens(recognizer)
+	expecting := d.hooks().GetExpectedTokens(recognizer)
 	if expecting.contains(NextTokenType) {
-		d.ReportUnwantedToken(recognizer)
+		d.hooks().ReportUnwantedToken(recognizer)
 		// print("recoverFromMisMatchedToken deleting " \
 		// + str(recognizer.GetTokenStream().LT(1)) \
 		// + " since " + str(recognizer.GetTokenStream().LT(2)) \
@@ -482,7 +539,7 @@ func (d *DefaultErrorStrategy) SingleTokenDeletion(recognizer Parser) Token {
 		recognizer.Consume() // simply delete extra token
 		// we want to return the token we're actually Matching
 		MatchedSymbol := recognizer.GetCurrentToken()
-		d.ReportMatch(recognizer) // we know current token is correct
+		d.hooks().ReportMatch(recognizer) // we know current token is correct
 		return MatchedSymbol
 	}
 
@@ -510,19 +567,14 @@ func (d *DefaultErrorStrategy) SingleTokenDeletion(recognizer Parser) Token {
 //
 func (d *DefaultErrorStrategy) GetMissingSymbol(recognizer Parser) Token {
 	currentSymbol := recognizer.GetCurrentToken()
-	expecting := d.GetExpectedTokens(recognizer)
+	expecting := d.hooks().GetExpectedTokens(recognizer)
 	expectedTokenType := expecting.first()
 	var tokenText string
 
 	if expectedTokenType == TokenEOF {
 		tokenText = "<missing EOF>"
 	} else {
-		ln := recognizer.GetLiteralNames()
-		if expectedTokenType > 0 && expectedTokenType < len(ln) {
-			tokenText = "<missing " + recognizer.GetLiteralNames()[expectedTokenType] + ">"
-		} else {
-			tokenText = "<missing undefined>" // TODO matches the JS impl
-		}
+		tokenText = "<missing " + getRecognizerVocabulary(recognizer).GetDisplayName(expectedTokenType) + ">"
 	}
 	current := currentSymbol
 	lookback := recognizer.GetTokenStream().LT(-1)
@@ -661,7 +713,7 @@ func (d *DefaultErrorStrategy) escapeWSAndQuote(s string) string {
 // Like Grosch I implement context-sensitive FOLLOW sets that are combined
 // at run-time upon error to avoid overhead during parsing.
 //
-func (d *DefaultErrorStrategy) getErrorRecoverySet(recognizer Parser) *IntervalSet {
+func (d *DefaultErrorStrategy) GetErrorRecoverySet(recognizer Parser) *IntervalSet {
 	atn := recognizer.GetInterpreter().atn
 	ctx := recognizer.GetParserRuleContext()
 	recoverSet := NewIntervalSet()
@@ -678,7 +730,7 @@ func (d *DefaultErrorStrategy) getErrorRecoverySet(recognizer Parser) *IntervalS
 }
 
 // Consume tokens until one Matches the given token set.//
-func (d *DefaultErrorStrategy) consumeUntil(recognizer Parser, set *IntervalSet) {
+func (d *DefaultErrorStrategy) ConsumeUntil(recognizer Parser, set *IntervalSet) {
 	ttype := recognizer.GetTokenStream().LA(1)
 	for ttype != TokenEOF && !set.contains(ttype) {
 		recognizer.Consume()
@@ -718,13 +770,14 @@ type BailErrorStrategy struct {
 	*DefaultErrorStrategy
 }
 
-var _ ErrorStrategy = &BailErrorStrategy{}
+var _ ErrorStrategyHooks = &BailErrorStrategy{}
 
 func NewBailErrorStrategy() *BailErrorStrategy {
 
 	b := new(BailErrorStrategy)
 
 	b.DefaultErrorStrategy = NewDefaultErrorStrategy()
+	b.Virt = b
 
 	return b
 }
@@ -0,0 +1,83 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"strings"
+	"testing"
+)
+
+// missingTokenStrategy records missing tokens instead of reporting them.
+type missingTokenStrategy struct {
+	*DefaultErrorStrategy
+
+	missing []string
+}
+
+func newMissingTokenStrategy(virtual bool) *missingTokenStrategy {
+	s := &missingTokenStrategy{DefaultErrorStrategy: NewDefaultErrorStrategy()}
+
+	if virtual {
+		s.Virt = s
+	}
+
+	return s
+}
+
+func (s *missingTokenStrategy) ReportMissingToken(recognizer Parser) {
+	if s.InErrorRecoveryMode(recognizer) {
+		return
+	}
+
+	s.BeginErrorCondition(recognizer)
+
+	expecting := s.GetExpectedTokens(recognizer)
+	s.missing = append(s.missing, expecting.StringVerbose(recognizer.GetLiteralNames(), recognizer.GetSymbolicNames(), false)+" before "+s.GetTokenErrorDisplay(recognizer.GetCurrentToken()))
+}
+
+// parseCalcWithStrategy parses text with s and returns the tree and the
+// syntax errors reported to the listeners.
+func parseCalcWithStrategy(text string, s ErrorStrategy) (string, []string) {
+	lexer := NewCalcLexer(NewInputStream(text))
+	p := NewCalcParser(NewCommonTokenStream(lexer, TokenDefaultChannel))
+	errors := &calcErrorRecorder{DefaultErrorListener: NewDefaultErrorListener()}
+
+	p.RemoveErrorListeners()
+	p.AddErrorListener(errors)
+	p.SetErrorHandler(s)
+
+	return p.Prog().ToStringTree(nil, p), errors.errors
+}
+
+func TestDefaultErrorStrategyCallsVirtHooks(t *testing.T) {
+	// The ')' is missing: RecoverInline recovers by single token insertion
+	// and reports the missing token through Virt
+	s := newMissingTokenStrategy(true)
+	tree, errors := parseCalcWithStrategy("print (a;", s)
+
+	if want := "(prog (stat print (expr (atom ( (expr (atom a)) <missing ')'>)) ;) <EOF>)"; tree != want {
+		t.Errorf("got %s, want %s", tree, want)
+	}
+
+	if len(errors) != 0 {
+		t.Errorf("got errors %v, want them recorded by the override", errors)
+	}
+
+	if want := "')' before ';'"; len(s.missing) != 1 || s.missing[0] != want {
+		t.Errorf("got missing tokens %q, want %q", s.missing, want)
+	}
+
+	// Without Virt the default report is used
+	s = newMissingTokenStrategy(false)
+	_, errors = parseCalcWithStrategy("print (a;", s)
+
+	if len(s.missing) != 0 {
+		t.Errorf("override called without Virt: %q", s.missing)
+	}
+
+	if len(errors) != 1 || !strings.Contains(errors[0], "missing ')' at ';'") {
+		t.Errorf("got errors %v", errors)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+// IncludeHandler is called by an IncludeTokenSource with each token other
+// than EOF. It may push a nested source for an include directive, and returns
+// false to drop the token, such as the directive itself.
+type IncludeHandler func(s *IncludeTokenSource, t Token) bool
+
+// IncludeTokenSource is a TokenSource for inputs that include other inputs. It
+// keeps a stack of sources: tokens come from the source on top, and when a
+// nested source reaches EOF it is dropped, without its EOF token, and the
+// source that included it carries on. Sources are pushed by a lexer action or
+// an IncludeHandler when they meet an include directive.
+//
+// The tokens keep the source and input they were lexed from, so their
+// GetSource, GetInputStream and GetLine tell the file and line they come
+// from; ErrorSourceName finds the file of a reported error.
+type IncludeTokenSource struct {
+	stack    []TokenSource
+	newLexer func(input CharStream) Lexer
+	handler  IncludeHandler
+}
+
+var _ TokenSource = &IncludeTokenSource{}
+
+// NewIncludeTokenSource returns an IncludeTokenSource reading source, usually
+// the lexer of the main input. PushInput makes the lexers of included inputs
+// with newLexer, which may be nil if only Push is used.
+func NewIncludeTokenSource(source TokenSource, newLexer func(input CharStream) Lexer) *IncludeTokenSource {
+	return &IncludeTokenSource{
+		stack:    []TokenSource{source},
+		newLexer: newLexer,
+	}
+}
+
+// SetIncludeHandler sets the function called with each token, or nil for none.
+func (s *IncludeTokenSource) SetIncludeHandler(handler IncludeHandler) {
+	s.handler = handler
+}
+
+// Push makes source the source of the next tokens until it reaches EOF.
+func (s *IncludeTokenSource) Push(source TokenSource) {
+	source.SetTokenFactory(s.GetTokenFactory())
+
+	s.stack = append(s.stack, source)
+}
+
+// PushInput pushes a lexer made for input, as Push does. The lexer reports
+// its errors to the error listeners of the main source, if that is a lexer,
+// instead of its own. Those are the listeners the main source has at the time
+// of the push: listeners added or removed later are not told of the errors of
+// the lexers already pushed.
+//
+// When a lexer action pushes the input, the token of the directive must be
+// emitted, on a hidden channel if need be, not skipped: a lexer that skips it
+// goes on to lex the token after it before the included tokens are read.
+func (s *IncludeTokenSource) PushInput(input CharStream) {
+	if s.newLexer == nil {
+		panic("IncludeTokenSource has no function to make lexers with")
+	}
+
+	lexer := s.newLexer(input)
+
+	if main, ok := s.stack[0].(Recognizer); ok {
+		lexer.RemoveErrorListeners()
+		lexer.AddErrorListener(main.GetErrorListenerDispatch())
+	}
+
+	s.Push(lexer)
+}
+
+// GetDepth returns the number of includes the next token is nested in.
+func (s *IncludeTokenSource) GetDepth() int {
+	return len(s.stack) - 1
+}
+
+// GetCurrentSource returns the source on top of the stack.
+func (s *IncludeTokenSource) GetCurrentSource() TokenSource {
+	return s.stack[len(s.stack)-1]
+}
+
+func (s *IncludeTokenSource) NextToken() Token {
+	for {
+		// An action may push a source while the current one lexes a token
+		i := len(s.stack) - 1
+		t := s.stack[i].NextToken()
+
+		if t.GetTokenType() == TokenEOF {
+			if i == 0 {
+				return t
+			}
+
+			s.stack = append(s.stack[:i], s.stack[i+1:]...)
+
+			continue
+		}
+
+		if s.handler == nil || s.handler(s, t) {
+			return t
+		}
+	}
+}
+
+func (s *IncludeTokenSource) Skip() {
+	s.GetCurrentSource().Skip()
+}
+
+func (s *IncludeTokenSource) More() {
+	s.GetCurrentSource().More()
+}
+
+func (s *IncludeTokenSource) GetLine() int {
+	return s.GetCurrentSource().GetLine()
+}
+
+func (s *IncludeTokenSource) GetCharPositionInLine() int {
+	return s.GetCurrentSource().GetCharPositionInLine()
+}
+
+func (s *IncludeTokenSource) GetInputStream() CharStream {
+	return s.GetCurrentSource().GetInputStream()
+}
+
+// GetSourceName returns the name of the input being read.
+func (s *IncludeTokenSource) GetSourceName() string {
+	if input := s.GetInputStream(); input != nil {
+		return input.GetSourceName()
+	}
+
+	return s.GetCurrentSource().GetSourceName()
+}
+
+// SetTokenFactory sets the token factory of all the sources on the stack and
+// of those pushed later.
+func (s *IncludeTokenSource) SetTokenFactory(factory TokenFactory) {
+	for _, source := range s.stack {
+		source.SetTokenFactory(factory)
+	}
+}
+
+func (s *IncludeTokenSource) GetTokenFactory() TokenFactory {
+	return s.stack[0].GetTokenFactory()
+}
+
+// ErrorSourceName returns the name of the input a syntax error reported to an
+// ErrorListener was found in: the input of the offending token of a parser
+// error, or of the lexer that reported a token recognition error. With an
+// IncludeTokenSource, it is the name of the included input.
+func ErrorSourceName(recognizer Recognizer, offendingSymbol interface{}) string {
+	if t, ok := offendingSymbol.(Token); ok && t != nil {
+		if input := t.GetInputStream(); input != nil {
+			return input.GetSourceName()
+		}
+	}
+
+	if source, ok := recognizer.(TokenSource); ok {
+		if input := source.GetInputStream(); input != nil {
+			return input.GetSourceName()
+		}
+	}
+
+	if p, ok := recognizer.(Parser); ok && p.GetTokenStream() != nil {
+		// The source name of a lexer is its grammar, so ask for its input
+		source := p.GetTokenStream().GetTokenSource()
+
+		if input := source.GetInputStream(); input != nil {
+			return input.GetSourceName()
+		}
+
+		return source.GetSourceName()
+	}
+
+	return ""
+}
@@ -0,0 +1,289 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// namedInputStream is an InputStream with the name of the file it stands for.
+type namedInputStream struct {
+	*InputStream
+	name string
+}
+
+func (s *namedInputStream) GetSourceName() string {
+	return s.name
+}
+
+// calcIncludeFiles are the inputs of the include tests: main includes a,
+// which includes b.
+var calcIncludeFiles = map[string]string{
+	"main": "x = 1;\n#include a\ny;\n",
+	"a":    "print \"s\";\n#include b\nz;\n",
+	"b":    "\n  w;\n",
+}
+
+func calcIncludeInput(name string) CharStream {
+	return &namedInputStream{NewInputStream(calcIncludeFiles[name]), name}
+}
+
+// calcIncludedTokens describes the tokens of the default channel by the name
+// of their input, their position and their text.
+const calcIncludedTokens = `main 1:0 "x"
+main 1:2 "="
+main 1:4 "1"
+main 1:5 ";"
+a 1:0 "print"
+a 1:6 "\""
+a 1:7 "s"
+a 1:8 "\""
+a 1:9 ";"
+b 2:2 "w"
+b 2:3 ";"
+a 3:0 "z"
+a 3:1 ";"
+main 3:0 "y"
+main 3:1 ";"
+main 4:0 "<EOF>"
+`
+
+// newCalcIncludeSource returns an IncludeTokenSource reading the main input
+// whose lexers push the inputs they include from the action of INCLUDE.
+func newCalcIncludeSource() (*IncludeTokenSource, *CalcLexer) {
+	var s *IncludeTokenSource
+
+	newLexer := func(input CharStream) *CalcLexer {
+		l := NewCalcLexer(input)
+
+		l.Include = func(name string) {
+			s.PushInput(calcIncludeInput(name))
+		}
+
+		return l
+	}
+
+	lexer := newLexer(calcIncludeInput("main"))
+	s = NewIncludeTokenSource(lexer, func(input CharStream) Lexer { return newLexer(input) })
+
+	return s, lexer
+}
+
+func describeIncludedTokens(tokens []Token) string {
+	var buf strings.Builder
+
+	for _, t := range tokens {
+		if t.GetChannel() == TokenDefaultChannel {
+			fmt.Fprintf(&buf, "%s %d:%d %q\n", t.GetInputStream().GetSourceName(), t.GetLine(), t.GetColumn(), t.GetText())
+		}
+	}
+
+	return buf.String()
+}
+
+func TestIncludeTokenSourcePushFromLexerAction(t *testing.T) {
+	s, _ := newCalcIncludeSource()
+	stream := NewCommonTokenStream(s, TokenDefaultChannel)
+
+	stream.Fill()
+
+	if got := describeIncludedTokens(stream.GetAllTokens()); got != calcIncludedTokens {
+		t.Errorf("got\n%s\nwant\n%s", got, calcIncludedTokens)
+	}
+
+	// The EOF tokens of the included inputs are dropped
+	eofs := 0
+
+	for _, token := range stream.GetAllTokens() {
+		if token.GetTokenType() == TokenEOF {
+			eofs++
+		}
+	}
+
+	if eofs != 1 {
+		t.Errorf("got %d EOF tokens, want 1", eofs)
+	}
+
+	if d := s.GetDepth(); d != 0 {
+		t.Errorf("got depth %d at EOF, want 0", d)
+	}
+}
+
+func TestIncludeTokenSourcePushFromHandler(t *testing.T) {
+	lexer := NewCalcLexer(calcIncludeInput("main"))
+	s := NewIncludeTokenSource(lexer, nil)
+	depths := make([]string, 0)
+
+	lexer.Include = func(string) {}
+
+	s.SetIncludeHandler(func(s *IncludeTokenSource, t Token) bool {
+		if t.GetChannel() == TokenDefaultChannel {
+			depths = append(depths, fmt.Sprintf("%s %d", s.GetSourceName(), s.GetDepth()))
+		}
+
+		if t.GetTokenType() != CalcLexerINCLUDE {
+			return true
+		}
+
+		// Push a lexer that also handles its include directives
+		text := t.GetText()
+		l := NewCalcLexer(calcIncludeInput(text[strings.LastIndex(text, " ")+1:]))
+
+		l.Include = func(string) {}
+		s.Push(l)
+
+		return false
+	})
+
+	stream := NewCommonTokenStream(s, TokenDefaultChannel)
+
+	stream.Fill()
+
+	if got := describeIncludedTokens(stream.GetAllTokens()); got != calcIncludedTokens {
+		t.Errorf("got\n%s\nwant\n%s", got, calcIncludedTokens)
+	}
+
+	for _, token := range stream.GetAllTokens() {
+		if token.GetTokenType() == CalcLexerINCLUDE {
+			t.Errorf("include directive %q not dropped", token.GetText())
+		}
+	}
+
+	// The handler is not called with EOF
+	want := "main 0\nmain 0\nmain 0\nmain 0\na 1\na 1\na 1\na 1\na 1\nb 2\nb 2\na 1\na 1\nmain 0\nmain 0"
+
+	if got := strings.Join(depths, "\n"); got != want {
+		t.Errorf("got sources and depths\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestIncludeTokenSourcePushInputWithoutLexers(t *testing.T) {
+	s := NewIncludeTokenSource(NewCalcLexer(calcIncludeInput("main")), nil)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic")
+		}
+	}()
+
+	s.PushInput(calcIncludeInput("a"))
+}
+
+// calcErrorRecorder records syntax errors by the name of the input they are
+// found in and their position.
+type calcErrorRecorder struct {
+	*DefaultErrorListener
+	errors []string
+}
+
+func (r *calcErrorRecorder) SyntaxError(recognizer Recognizer, offendingSymbol interface{}, line, column int, msg string, e RecognitionException) {
+	r.errors = append(r.errors, fmt.Sprintf("%s %d:%d %s", ErrorSourceName(recognizer, offendingSymbol), line, column, msg))
+}
+
+func TestIncludeTokenSourceErrorListeners(t *testing.T) {
+	calcIncludeFiles["c"] = "v = ;\n\n@ u;\n"
+	defer delete(calcIncludeFiles, "c")
+
+	var s *IncludeTokenSource
+
+	main := NewCalcLexer(&namedInputStream{NewInputStream("x;\n#include c\n= y;\n"), "main"})
+	lexerErrors := &calcErrorRecorder{DefaultErrorListener: NewDefaultErrorListener()}
+
+	main.RemoveErrorListeners()
+	main.AddErrorListener(lexerErrors)
+
+	main.Include = func(name string) {
+		s.PushInput(calcIncludeInput(name))
+	}
+
+	s = NewIncludeTokenSource(main, func(input CharStream) Lexer {
+		l := NewCalcLexer(input)
+
+		// Listeners of the included lexer are replaced by those of main
+		l.AddErrorListener(&calcErrorRecorder{DefaultErrorListener: NewDefaultErrorListener()})
+
+		return l
+	})
+
+	p := NewCalcParser(NewCommonTokenStream(s, TokenDefaultChannel))
+	parserErrors := &calcErrorRecorder{DefaultErrorListener: NewDefaultErrorListener()}
+
+	p.RemoveErrorListeners()
+	p.AddErrorListener(parserErrors)
+	p.Prog()
+
+	if got, want := strings.Join(lexerErrors.errors, "\n"), "c 3:0 token recognition error at: '@'"; got != want {
+		t.Errorf("got lexer errors\n%s\nwant\n%s", got, want)
+	}
+
+	want := "c 1:4 extraneous input ';' expecting {'(', ID, INT, QUOTE}\nmain 3:0 extraneous input '=' expecting {<EOF>, 'print', '(', '{', ID, INT, QUOTE}"
+
+	if got := strings.Join(parserErrors.errors, "\n"); got != want {
+		t.Errorf("got parser errors\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestIncludeTokenSourceErrorListenersAtPush(t *testing.T) {
+	calcIncludeFiles["c"] = "@"
+	defer delete(calcIncludeFiles, "c")
+
+	s, main := newCalcIncludeSource()
+	before := &calcErrorRecorder{DefaultErrorListener: NewDefaultErrorListener()}
+	after := &calcErrorRecorder{DefaultErrorListener: NewDefaultErrorListener()}
+
+	main.RemoveErrorListeners()
+	main.AddErrorListener(before)
+	s.PushInput(calcIncludeInput("c"))
+
+	// The included lexer reports to the listeners main had when it was pushed
+	main.AddErrorListener(after)
+
+	stream := NewCommonTokenStream(s, TokenDefaultChannel)
+
+	stream.Fill()
+
+	if got, want := strings.Join(before.errors, "\n"), "c 1:0 token recognition error at: '@'"; got != want {
+		t.Errorf("got errors\n%s\nwant\n%s", got, want)
+	}
+
+	if len(after.errors) != 0 {
+		t.Errorf("listener added after the push got %v", after.errors)
+	}
+}
+
+func TestErrorSourceName(t *testing.T) {
+	lexer := NewCalcLexer(calcIncludeInput("a"))
+	stream := NewCommonTokenStream(lexer, TokenDefaultChannel)
+	p := NewCalcParser(stream)
+
+	stream.Fill()
+
+	tests := []struct {
+		recognizer      Recognizer
+		offendingSymbol interface{}
+		want            string
+	}{
+		// The input of the offending token
+		{p, stream.Get(0), "a"},
+		// The input of a lexer
+		{lexer, nil, "a"},
+		// The input of the tokens of a parser
+		{p, nil, "a"},
+		// A token without an input
+		{p, NewCommonToken(&TokenSourceCharStreamPair{}, CalcLexerID, TokenDefaultChannel, 0, 0), "a"},
+	}
+
+	for i, test := range tests {
+		if got := ErrorSourceName(test.recognizer, test.offendingSymbol); got != test.want {
+			t.Errorf("%d: got %q, want %q", i, got, test.want)
+		}
+	}
+
+	if got := ErrorSourceName(NewCalcParser(nil), nil); got != "" {
+		t.Errorf("got %q without a token stream, want \"\"", got)
+	}
+}
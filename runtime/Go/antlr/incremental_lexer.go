@@ -0,0 +1,282 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"errors"
+	"sort"
+)
+
+// TextEdit replaces DeletedLength chars at Offset with InsertedText. Offsets
+// and lengths count chars, as token start and stop indexes do.
+type TextEdit struct {
+	Offset        int
+	DeletedLength int
+	InsertedText  string
+}
+
+// TokenChange describes how IncrementalLexer.Update changed the tokens: the
+// old tokens from Start up to OldEnd were replaced by the new tokens from
+// Start up to NewEnd. The tokens after them are the old tokens, shifted.
+type TokenChange struct {
+	Start  int
+	OldEnd int
+	NewEnd int
+}
+
+// IncrementalLexer keeps the tokens of a text up to date as the text is
+// edited, re-lexing only the part of the text an edit can affect. For each
+// token it remembers the LexerState the token was lexed from and how far the
+// lexer looked ahead, so it knows where re-lexing must start, and it stops
+// re-lexing as soon as the lexer gets back into the state it was in at the
+// same point of the old text.
+type IncrementalLexer struct {
+	lexer  *BaseLexer
+	text   []rune
+	input  *reachCharStream
+	tokens []Token
+	states []LexerState
+	reach  []int
+}
+
+// NewIncrementalLexer lexes text with lexer, which is taken over by the
+// IncrementalLexer, on all channels.
+func NewIncrementalLexer(lexer *BaseLexer, text string) *IncrementalLexer {
+	l := &IncrementalLexer{lexer: lexer}
+
+	l.setText([]rune(text))
+
+	run := l.lexFrom(0, nil)
+
+	l.tokens, l.states, l.reach = run.tokens, run.states, run.reach
+
+	return l
+}
+
+// GetTokens returns the tokens of the text, numbered from 0 and ending with
+// the EOF token, as CommonTokenStream buffers them.
+func (l *IncrementalLexer) GetTokens() []Token {
+	return l.tokens
+}
+
+// GetTokenStream returns a CommonTokenStream over the current tokens for a
+// parser to read on channel, such as for IncrementalParser.Parse.
+func (l *IncrementalLexer) GetTokenStream(channel int) *CommonTokenStream {
+	tokens := make([]Token, len(l.tokens))
+
+	copy(tokens, l.tokens)
+
+	return &CommonTokenStream{
+		channel:     channel,
+		fetchedEOF:  true,
+		index:       -1,
+		tokenSource: l.lexer.Virt,
+		tokens:      tokens,
+	}
+}
+
+func (l *IncrementalLexer) GetText() string {
+	return string(l.text)
+}
+
+// Update applies edits, whose offsets all refer to the text before any of them
+// is applied and which must not overlap, and re-lexes the affected tokens. The
+// tokens that follow keep their identity; their indexes and positions are
+// shifted.
+func (l *IncrementalLexer) Update(edits ...TextEdit) (*TokenChange, error) {
+	if len(edits) == 0 {
+		return &TokenChange{len(l.tokens), len(l.tokens), len(l.tokens)}, nil
+	}
+
+	sorted := make([]TextEdit, len(edits))
+
+	copy(sorted, edits)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Offset < sorted[j].Offset })
+
+	// Build the new text and find the region of the old text that changed
+	text := make([]rune, 0, len(l.text))
+	pos := 0
+
+	for _, e := range sorted {
+		if e.Offset < pos || e.DeletedLength < 0 || e.Offset+e.DeletedLength > len(l.text) {
+			return nil, errors.New("antlr: text edits must be within the text and must not overlap")
+		}
+
+		text = append(text, l.text[pos:e.Offset]...)
+		text = append(text, []rune(e.InsertedText)...)
+		pos = e.Offset + e.DeletedLength
+	}
+
+	text = append(text, l.text[pos:]...)
+
+	lo := sorted[0].Offset
+	hi := pos
+	delta := len(text) - len(l.text)
+
+	// Re-lex from the first token whose lexing looked at a changed char
+	start := 0
+
+	for start < len(l.tokens)-1 && l.reach[start] < lo {
+		start++
+	}
+
+	oldStates := l.states
+
+	l.setText(text)
+
+	converge := func(s LexerState) int {
+		oldIndex := s.CharIndex - delta
+
+		if oldIndex < hi {
+			return -1
+		}
+
+		j := sort.Search(len(oldStates), func(k int) bool { return oldStates[k].CharIndex >= oldIndex })
+
+		if j == len(oldStates) || j <= start || oldStates[j].CharIndex != oldIndex {
+			return -1
+		}
+
+		if oldStates[j].HitEOF != s.HitEOF || !oldStates[j].EqualsModes(s) {
+			return -1
+		}
+
+		return j
+	}
+
+	run := l.lexFrom(start, converge)
+	end := start + len(run.tokens)
+	change := &TokenChange{Start: start, OldEnd: len(l.tokens), NewEnd: end}
+	tail := 0
+
+	if j := run.converged; j >= 0 {
+		old := oldStates[j]
+
+		change.OldEnd = j
+		tail = len(l.tokens) - j
+
+		l.shiftTokens(j, end-j, delta, run.resume.Line-old.Line, old.Line, run.resume.Column-old.Column)
+	}
+
+	l.tokens = append(append(l.tokens[:start:start], run.tokens...), l.tokens[len(l.tokens)-tail:]...)
+	l.states = append(append(l.states[:start:start], run.states...), l.states[len(l.states)-tail:]...)
+	l.reach = append(append(l.reach[:start:start], run.reach...), l.reach[len(l.reach)-tail:]...)
+
+	return change, nil
+}
+
+func (l *IncrementalLexer) setText(text []rune) {
+	l.text = text
+	l.input = &reachCharStream{CharStream: NewInputStream(string(text))}
+
+	l.lexer.SetInputStream(l.input)
+}
+
+// lexRun is the result of IncrementalLexer.lexFrom: the tokens lexed, the
+// state before each of them and how far the lexer looked for each. If lexing
+// stopped before EOF, converged is the index of the old token from which the
+// old tokens carry on and resume is the state of the lexer there; otherwise
+// converged is -1.
+type lexRun struct {
+	tokens    []Token
+	states    []LexerState
+	reach     []int
+	converged int
+	resume    LexerState
+}
+
+// lexFrom lexes from the state of token start, or from the start of the text
+// if there are no tokens yet, numbering the tokens from start, until EOF or
+// until converge returns the index of an old token whose state the lexer is
+// in.
+func (l *IncrementalLexer) lexFrom(start int, converge func(LexerState) int) *lexRun {
+	if start < len(l.states) {
+		l.lexer.Restore(l.states[start])
+	}
+
+	run := &lexRun{
+		tokens:    make([]Token, 0),
+		states:    make([]LexerState, 0),
+		reach:     make([]int, 0),
+		converged: -1,
+	}
+
+	for {
+		s := l.lexer.Snapshot()
+
+		if converge != nil && len(run.tokens) > 0 {
+			if j := converge(s); j >= 0 {
+				run.converged = j
+				run.resume = s
+
+				return run
+			}
+		}
+
+		l.input.reach = -1
+
+		t := l.lexer.Virt.NextToken()
+
+		t.SetTokenIndex(start + len(run.tokens))
+
+		run.tokens = append(run.tokens, t)
+		run.states = append(run.states, s)
+		run.reach = append(run.reach, intMax(l.input.reach, t.GetStop()))
+
+		if t.GetTokenType() == TokenEOF {
+			return run
+		}
+	}
+}
+
+// shiftTokens moves the old tokens from j on to their place in the new text:
+// indexes by indexDelta, char offsets by delta, lines by lineDelta and, on
+// line, the line where re-lexing converged, columns by columnDelta.
+func (l *IncrementalLexer) shiftTokens(j, indexDelta, delta, lineDelta, line, columnDelta int) {
+	source := l.lexer.GetTokenSourceCharStreamPair()
+
+	for k := j; k < len(l.tokens); k++ {
+		s := &l.states[k]
+
+		if s.Line == line {
+			s.Column += columnDelta
+		}
+
+		s.CharIndex += delta
+		s.Line += lineDelta
+
+		l.reach[k] += delta
+
+		t := l.tokens[k]
+
+		t.SetTokenIndex(t.GetTokenIndex() + indexDelta)
+
+		if ct, ok := t.(*CommonToken); ok {
+			if ct.line == line {
+				ct.column += columnDelta
+			}
+
+			ct.start += delta
+			ct.stop += delta
+			ct.line += lineDelta
+			ct.source = source
+		}
+	}
+}
+
+// reachCharStream records the index of the furthest char looked at.
+type reachCharStream struct {
+	CharStream
+
+	reach int
+}
+
+func (s *reachCharStream) LA(i int) int {
+	if i > 0 {
+		s.reach = intMax(s.reach, s.Index()+i-1)
+	}
+
+	return s.CharStream.LA(i)
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+const calcIncrementalText = `x = a + 1;
+print "one", "two
+three" + b;
+{ "s" : y; }
+z = "";
+`
+
+// calcEditChars are the chars random edits insert. '#' is left out so that
+// no INCLUDE token is lexed.
+const calcEditChars = "ab1 \n\n\"\"+;=(){}:print"
+
+func lexCalc(text string) string {
+	stream := NewCommonTokenStream(NewCalcLexer(NewInputStream(text)), TokenDefaultChannel)
+
+	stream.Fill()
+
+	return tokenDescriptions(stream.GetAllTokens())
+}
+
+// randomTextEdit returns an edit of text of one of the kinds the tests cover:
+// anywhere, at the start, at the end or just inside a string.
+func randomTextEdit(r *rand.Rand, text []rune) TextEdit {
+	offset := r.Intn(len(text) + 1)
+
+	switch r.Intn(5) {
+	case 0:
+		offset = 0
+
+	case 1:
+		offset = len(text)
+
+	case 2:
+		quotes := make([]int, 0)
+
+		for i, c := range text {
+			if c == '"' {
+				quotes = append(quotes, i+1)
+			}
+		}
+
+		if len(quotes) > 0 {
+			offset = quotes[r.Intn(len(quotes))]
+		}
+	}
+
+	deleted := 0
+
+	if offset < len(text) {
+		deleted = r.Intn(intMin(len(text)-offset, 8) + 1)
+	}
+
+	inserted := make([]byte, r.Intn(8))
+
+	for i := range inserted {
+		inserted[i] = calcEditChars[r.Intn(len(calcEditChars))]
+	}
+
+	// Sometimes insert several lines at once
+	if r.Intn(4) == 0 {
+		inserted = append(inserted, "\nq = \"r\ns\";\n"...)
+	}
+
+	return TextEdit{Offset: offset, DeletedLength: deleted, InsertedText: string(inserted)}
+}
+
+func applyTextEdits(text []rune, edits []TextEdit) []rune {
+	result := make([]rune, 0)
+	pos := 0
+
+	for _, e := range edits {
+		result = append(result, text[pos:e.Offset]...)
+		result = append(result, []rune(e.InsertedText)...)
+		pos = e.Offset + e.DeletedLength
+	}
+
+	return append(result, text[pos:]...)
+}
+
+func TestIncrementalLexerMatchesFullLex(t *testing.T) {
+	r := rand.New(rand.NewSource(45))
+
+	for run := 0; run < 20; run++ {
+		text := []rune(calcIncrementalText)
+		l := NewIncrementalLexer(NewCalcLexer(nil).BaseLexer, string(text))
+
+		if got, want := tokenDescriptions(l.GetTokens()), lexCalc(string(text)); got != want {
+			t.Fatalf("initial tokens:\n%s\nwant:\n%s", got, want)
+		}
+
+		for step := 0; step < 50; step++ {
+			var edits []TextEdit
+
+			if r.Intn(4) == 0 && len(text) > 1 {
+				// Two edits, both offsets referring to the old text
+				split := 1 + r.Intn(len(text)-1)
+				first := randomTextEdit(r, text[:split])
+				second := randomTextEdit(r, text[split:])
+
+				if first.Offset+first.DeletedLength > split {
+					first.DeletedLength = split - first.Offset
+				}
+
+				second.Offset += split
+				edits = []TextEdit{first, second}
+			} else {
+				edits = []TextEdit{randomTextEdit(r, text)}
+			}
+
+			oldTokens := l.GetTokens()
+			change, err := l.Update(edits...)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			text = applyTextEdits(text, edits)
+
+			if l.GetText() != string(text) {
+				t.Fatalf("got text %q, want %q", l.GetText(), string(text))
+			}
+
+			if got, want := tokenDescriptions(l.GetTokens()), lexCalc(string(text)); got != want {
+				t.Fatalf("run %d step %d: after %+v on %q got:\n%s\nwant:\n%s", run, step, edits, string(text), got, want)
+			}
+
+			// The tokens before the change are kept as they are
+			for i := 0; i < change.Start; i++ {
+				if l.GetTokens()[i] != oldTokens[i] {
+					t.Fatalf("run %d step %d: token %d before the change was replaced", run, step, i)
+				}
+			}
+		}
+	}
+}
+
+func TestIncrementalLexerEdits(t *testing.T) {
+	tests := []struct {
+		name  string
+		edits []TextEdit
+	}{
+		{"insert lines at start", []TextEdit{{0, 0, "a;\n\nb = \"c\nd\";\n"}}},
+		{"delete at start", []TextEdit{{0, 5, ""}}},
+		{"insert lines at end", []TextEdit{{len(calcIncrementalText), 0, "q;\n\"r\ns\"\n"}}},
+		{"delete at end", []TextEdit{{len(calcIncrementalText) - 7, 7, ""}}},
+		{"insert inside string", []TextEdit{{strings.Index(calcIncrementalText, "two") + 1, 0, "x\"y\"\n"}}},
+		{"delete string start", []TextEdit{{strings.Index(calcIncrementalText, "\"one"), 1, ""}}},
+		{"delete string end", []TextEdit{{strings.Index(calcIncrementalText, "three\"") + 5, 1, ""}}},
+		{"join lines", []TextEdit{{strings.Index(calcIncrementalText, "\n"), 1, ""}}},
+		{"delete everything", []TextEdit{{0, len(calcIncrementalText), ""}}},
+	}
+
+	for _, test := range tests {
+		l := NewIncrementalLexer(NewCalcLexer(nil).BaseLexer, calcIncrementalText)
+
+		if _, err := l.Update(test.edits...); err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+
+		text := string(applyTextEdits([]rune(calcIncrementalText), test.edits))
+
+		if got, want := tokenDescriptions(l.GetTokens()), lexCalc(text); got != want {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", test.name, got, want)
+		}
+	}
+}
@@ -0,0 +1,579 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import "reflect"
+
+var (
+	parserType = reflect.TypeOf((*Parser)(nil)).Elem()
+	tokenType  = reflect.TypeOf((*Token)(nil)).Elem()
+	treeType   = reflect.TypeOf((*Tree)(nil)).Elem()
+)
+
+// IncrementalParser reparses a token stream after an edit, reusing the
+// subtrees of the previous parse tree that the edit cannot have changed. A
+// rule invocation reuses the old context when it starts at the same token, in
+// the same rule, called from the same chain of parser states at the same
+// precedence, and none of the tokens the old invocation matched or looked at
+// to make its decisions has changed. Instead of running the rule body, the
+// parser takes the old children and labels over into the new context and
+// skips to the token after the old one's stop token, so that the new tree is
+// the one a full parse would build.
+//
+// Subtrees are only reused if they have no syntax errors, and contexts of
+// left-recursive rules, of rules with alternative labels and of rules with
+// arguments, locals or return values are always reparsed, although their
+// children may be reused. Parse listeners do not see the nodes of reused
+// subtrees. Grammars whose actions or predicates depend on state outside the
+// parser cannot be parsed incrementally.
+//
+// A reused rule function returns by panicking with a RecognitionException
+// that the default recovery code of generated rule functions passes through.
+// Grammars with catch clauses on their rules must not be parsed incrementally
+// either, since their catch code replaces that recovery and would handle the
+// exception as a syntax error.
+type IncrementalParser struct {
+	parser *BaseParser
+
+	// The tokens of the last parse, with their indexes then
+	indexes map[Token]int
+
+	// The reusable contexts of the last parse tree, by start token
+	starts  map[Token][]*parseEntry
+	entries map[ParserRuleContext]*parseEntry
+
+	// The parse in progress
+	input    *reachTokenStream
+	tokens   []Token
+	damage   []int
+	frames   []parseFrame
+	recorded map[ParserRuleContext]*parseEntry
+	reused   int
+
+	// The entry whose subtree is to be reused for pendingCtx
+	pending    *parseEntry
+	pendingCtx ParserRuleContext
+
+	// Whether the fields of each context type are all labels
+	labelsOnly map[reflect.Type]bool
+}
+
+// parseEntry is what an IncrementalParser knows about a context of a parse
+// tree: the furthest token the rule invocation looked at, the precedence it
+// was called at and whether it, or any of its descendants, has errors.
+type parseEntry struct {
+	ctx        ParserRuleContext
+	reach      Token
+	precedence int
+	clean      bool
+}
+
+// parseFrame is the state of a rule invocation being parsed.
+type parseFrame struct {
+	reach      int // The reach of the caller so far
+	precedence int
+	errors     int // The syntax errors reported before the invocation
+	recovering bool
+}
+
+// parserRuleHook is called by BaseParser on entering and exiting a rule that
+// is not left-recursive, and on entering an alternative of any rule.
+type parserRuleHook interface {
+	enterRule(p *BaseParser, ctx ParserRuleContext, ruleIndex int)
+	exitRule(p *BaseParser, ctx ParserRuleContext)
+	skipRule(p *BaseParser)
+}
+
+// NewIncrementalParser returns an IncrementalParser for parser, the
+// BaseParser embedded in a generated parser.
+func NewIncrementalParser(parser *BaseParser) *IncrementalParser {
+	return &IncrementalParser{parser: parser, labelsOnly: make(map[reflect.Type]bool)}
+}
+
+// Parse parses input with startRule, a function calling the start rule of the
+// generated parser, and returns the tree. The first call parses all of input;
+// later calls reuse what they can of the tree returned by the previous call,
+// which is taken apart to build the new tree and must no longer be used. For
+// anything to be reused, input must hold the same Token values as the input of
+// the previous call wherever the text did not change, as the token streams of
+// an IncrementalLexer do.
+func (ip *IncrementalParser) Parse(input *CommonTokenStream, startRule func() ParserRuleContext) ParserRuleContext {
+	p := ip.parser
+
+	input.Fill()
+
+	ip.tokens = input.GetAllTokens()
+	ip.damage = ip.getDamage(ip.tokens)
+	ip.input = &reachTokenStream{TokenStream: input, reach: -1}
+	ip.frames = make([]parseFrame, 0)
+	ip.recorded = make(map[ParserRuleContext]*parseEntry)
+	ip.reused = 0
+
+	p.SetInputStream(ip.input)
+
+	handler := p.errHandler
+	done := false
+
+	p.errHandler = &incrementalErrorStrategy{handler, p}
+	p.ruleHook = ip
+
+	defer func() {
+		p.ruleHook = nil
+		p.errHandler = handler
+		p.input = input
+
+		if !done {
+			// The old tree may be half taken apart: start afresh next time
+			ip.indexes, ip.starts, ip.entries = nil, nil, nil
+		}
+
+		ip.input, ip.tokens, ip.damage, ip.frames, ip.recorded = nil, nil, nil, nil, nil
+		ip.pending, ip.pendingCtx = nil, nil
+	}()
+
+	tree := startRule()
+
+	ip.index(tree)
+
+	done = true
+
+	return tree
+}
+
+// GetReusedCount returns the number of subtrees the last call to Parse
+// reused.
+func (ip *IncrementalParser) GetReusedCount() int {
+	return ip.reused
+}
+
+// getDamage returns the running count, up to each index of tokens, of the
+// tokens that are new since the last parse or that follow deleted ones.
+func (ip *IncrementalParser) getDamage(tokens []Token) []int {
+	damage := make([]int, len(tokens)+1)
+
+	for k, t := range tokens {
+		d := 0
+
+		if old, ok := ip.indexes[t]; !ok {
+			d = 1
+		} else if k > 0 {
+			if prev, ok := ip.indexes[tokens[k-1]]; ok && prev != old-1 {
+				d = 1
+			}
+		}
+
+		damage[k+1] = damage[k] + d
+	}
+
+	return damage
+}
+
+func (ip *IncrementalParser) enterRule(p *BaseParser, ctx ParserRuleContext, ruleIndex int) {
+	frame := parseFrame{
+		reach:      ip.input.reach,
+		precedence: p.GetPrecedence(),
+		errors:     p._SyntaxErrors,
+		recovering: p.errHandler.InErrorRecoveryMode(p),
+	}
+
+	ip.frames = append(ip.frames, frame)
+	ip.input.reach = -1
+
+	if !p.BuildParseTrees || frame.recovering {
+		return
+	}
+
+	ip.pending = nil
+
+	for _, e := range ip.starts[ctx.GetStart()] {
+		if ip.canReuse(e, ctx, ruleIndex, frame.precedence) {
+			ip.pending = e
+			ip.pendingCtx = ctx
+
+			return
+		}
+	}
+}
+
+// skipRule reuses the pending subtree, if the parser is still in the context
+// it is pending for, by unwinding the generated rule function, whose recovery
+// code hands the exception to incrementalErrorStrategy. Generated rule
+// functions call EnterRule before they defer their recovery code, so this is
+// done the next time they call into the parser, on entering an alternative or
+// synchronizing before a decision.
+func (ip *IncrementalParser) skipRule(p *BaseParser) {
+	if ip.pending == nil || p.ctx != ip.pendingCtx {
+		return
+	}
+
+	ip.reuse(ip.pending, ip.pendingCtx)
+
+	ip.pending = nil
+
+	panic(&subtreeReusedException{p.ctx.GetStart()})
+}
+
+func (ip *IncrementalParser) exitRule(p *BaseParser, ctx ParserRuleContext) {
+	frame := ip.frames[len(ip.frames)-1]
+	ip.frames = ip.frames[:len(ip.frames)-1]
+	ip.pending = nil
+
+	reach := intMax(ip.input.reach, ctx.GetStart().GetTokenIndex())
+
+	if stop := ctx.GetStop(); stop != nil {
+		reach = intMax(reach, stop.GetTokenIndex())
+	}
+
+	reach = intMin(reach, len(ip.tokens)-1)
+
+	ip.recorded[ctx] = &parseEntry{
+		ctx:        ctx,
+		reach:      ip.tokens[reach],
+		precedence: frame.precedence,
+		clean:      p._SyntaxErrors == frame.errors && !frame.recovering,
+	}
+
+	ip.input.reach = intMax(frame.reach, reach)
+}
+
+// canReuse returns true if the old context of e can stand for the invocation
+// of rule ruleIndex that ctx is the context of.
+func (ip *IncrementalParser) canReuse(e *parseEntry, ctx ParserRuleContext, ruleIndex, precedence int) bool {
+	if !e.clean || e.precedence != precedence || e.ctx.GetRuleIndex() != ruleIndex {
+		return false
+	}
+
+	if reflect.TypeOf(e.ctx) != reflect.TypeOf(ctx) || !ip.hasOnlyLabels(reflect.TypeOf(ctx)) {
+		return false
+	}
+
+	start := ctx.GetStart().GetTokenIndex()
+	reach := e.reach.GetTokenIndex()
+
+	if reach < start || reach >= len(ip.tokens) || ip.tokens[reach] != e.reach {
+		return false
+	}
+
+	// The start token itself may follow deleted tokens
+	if ip.damage[reach+1] != ip.damage[start+1] {
+		return false
+	}
+
+	// The prediction of alternatives can depend on the invoking rules
+	a, b := ctx, e.ctx
+
+	for a != nil && b != nil {
+		if a.GetInvokingState() != b.GetInvokingState() || a.GetRuleIndex() != b.GetRuleIndex() {
+			return false
+		}
+
+		a, _ = a.GetParent().(ParserRuleContext)
+		b, _ = b.GetParent().(ParserRuleContext)
+	}
+
+	return a == nil && b == nil
+}
+
+// hasOnlyLabels returns true if the fields of the context type t, besides the
+// parser and the contexts t embeds, are all labels: tokens, rule contexts and
+// lists of them. Contexts of rules with arguments, locals or return values are
+// not reused, as the new invocation may differ from the old one in those
+// fields. A return value or local that is a token or a rule context cannot be
+// told from a label and does not prevent reuse.
+func (ip *IncrementalParser) hasOnlyLabels(t reflect.Type) bool {
+	if only, ok := ip.labelsOnly[t]; ok {
+		return only
+	}
+
+	only := contextHasOnlyLabels(t)
+	ip.labelsOnly[t] = only
+
+	return only
+}
+
+func contextHasOnlyLabels(t reflect.Type) bool {
+	if t == baseParserRuleContextPtrType {
+		return true
+	}
+
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return false
+	}
+
+	s := t.Elem()
+
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+
+		if f.Anonymous && f.Type.Implements(parserRuleContextType) {
+			if !contextHasOnlyLabels(f.Type) {
+				return false
+			}
+
+			continue
+		}
+
+		if f.Type == parserType {
+			continue
+		}
+
+		label := f.Type
+
+		if label.Kind() == reflect.Slice {
+			label = label.Elem()
+		}
+
+		isToken := label == tokenType
+		isTree := (label.Kind() == reflect.Interface || label.Kind() == reflect.Ptr) && label.Implements(treeType)
+
+		if !isToken && !isTree {
+			return false
+		}
+	}
+
+	return true
+}
+
+// reuse moves the children and labels of the old context of e to ctx and
+// skips the input to where the old context ends.
+func (ip *IncrementalParser) reuse(e *parseEntry, ctx ParserRuleContext) {
+	base := findBaseParserRuleContext(reflect.ValueOf(ctx))
+	oldBase := findBaseParserRuleContext(reflect.ValueOf(e.ctx))
+
+	copyContextFields(reflect.ValueOf(ctx), reflect.ValueOf(e.ctx))
+
+	for _, child := range base.children {
+		// Keep the parent the child had, as cloneTreeNode does
+		if prc, ok := child.GetParent().(*BaseParserRuleContext); ok && prc == oldBase {
+			child.SetParent(base)
+		} else {
+			child.SetParent(ctx)
+		}
+	}
+
+	// The old context is taken apart
+	e.clean = false
+
+	stop := e.ctx.GetStop()
+
+	if stop.GetTokenType() == TokenEOF {
+		ip.input.Seek(stop.GetTokenIndex())
+	} else {
+		ip.input.Seek(stop.GetTokenIndex() + 1)
+	}
+
+	ip.input.reach = e.reach.GetTokenIndex()
+	ip.reused++
+}
+
+// index records the reusable contexts of the new tree t and returns true if t
+// has errors.
+func (ip *IncrementalParser) index(t ParserRuleContext) bool {
+	starts := make(map[Token][]*parseEntry)
+	entries := make(map[ParserRuleContext]*parseEntry)
+
+	var walk func(t ParserRuleContext) bool
+
+	walk = func(t ParserRuleContext) bool {
+		bad := false
+
+		for _, child := range t.GetChildren() {
+			switch c := child.(type) {
+			case ErrorNode:
+				bad = true
+
+			case TerminalNode:
+				if c.GetSymbol().GetTokenIndex() < 0 {
+					// Conjured up by error recovery
+					bad = true
+				}
+
+			case ParserRuleContext:
+				if walk(c) {
+					bad = true
+				}
+			}
+		}
+
+		e, ok := ip.recorded[t]
+
+		if !ok {
+			// Inside a reused subtree
+			e, ok = ip.entries[t]
+		}
+
+		if !ok {
+			// Left-recursive rules have no entries
+			return bad
+		}
+
+		e.clean = e.clean && !bad
+		entries[t] = e
+
+		// Empty rules are quicker to reparse than to reuse
+		if stop := t.GetStop(); e.clean && stop != nil && stop.GetTokenIndex() >= t.GetStart().GetTokenIndex() {
+			starts[t.GetStart()] = append(starts[t.GetStart()], e)
+		}
+
+		return !e.clean
+	}
+
+	bad := walk(t)
+
+	indexes := make(map[Token]int, len(ip.tokens))
+
+	for k, tok := range ip.tokens {
+		indexes[tok] = k
+	}
+
+	ip.indexes, ip.starts, ip.entries = indexes, starts, entries
+
+	return bad
+}
+
+// findBaseParserRuleContext returns the BaseParserRuleContext that the
+// context v points to embeds, directly or through other contexts.
+func findBaseParserRuleContext(v reflect.Value) *BaseParserRuleContext {
+	if v.Type() == baseParserRuleContextPtrType {
+		return v.Interface().(*BaseParserRuleContext)
+	}
+
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	s := v.Elem()
+
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+
+		if s.Type().Field(i).Anonymous && f.Kind() == reflect.Ptr && f.Type().Implements(parserRuleContextType) {
+			if base := findBaseParserRuleContext(f); base != nil {
+				return base
+			}
+		}
+	}
+
+	return nil
+}
+
+// copyContextFields copies the children and the fields of the generated
+// context src, such as labels, to dst, a context of the same type. The start
+// and stop tokens, parent and invoking state of dst are left alone. Only
+// contexts whose fields are labels are reused, besides the parser, which is
+// the same for both, so copying the struct copies nothing but labels.
+func copyContextFields(dst, src reflect.Value) {
+	if dst.Type() == baseParserRuleContextPtrType {
+		d := dst.Interface().(*BaseParserRuleContext)
+		s := src.Interface().(*BaseParserRuleContext)
+
+		d.children = append([]Tree(nil), s.children...)
+		d.exception = s.exception
+
+		return
+	}
+
+	if dst.Kind() != reflect.Ptr || dst.Elem().Kind() != reflect.Struct {
+		return
+	}
+
+	d, s := dst.Elem(), src.Elem()
+
+	// Copy the struct as a whole, as its label fields are unexported, but
+	// keep the contexts dst embeds, which are copied into one by one
+	embedded := make(map[int]reflect.Value)
+
+	for i := 0; i < d.NumField(); i++ {
+		if df := d.Field(i); d.Type().Field(i).Anonymous && df.Kind() == reflect.Ptr && df.CanSet() && df.Type().Implements(parserRuleContextType) {
+			embedded[i] = reflect.ValueOf(df.Interface())
+		}
+	}
+
+	d.Set(s)
+
+	for i, df := range embedded {
+		d.Field(i).Set(df)
+
+		if sf := s.Field(i); !df.IsNil() && !sf.IsNil() {
+			copyContextFields(df, sf)
+		}
+	}
+}
+
+// subtreeReusedException is how IncrementalParser returns from a generated
+// rule function whose subtree it reused.
+type subtreeReusedException struct {
+	token Token
+}
+
+func (e *subtreeReusedException) GetOffendingToken() Token {
+	return e.token
+}
+
+func (e *subtreeReusedException) GetMessage() string {
+	return "subtree reused"
+}
+
+func (e *subtreeReusedException) GetInputStream() IntStream {
+	return nil
+}
+
+// incrementalErrorStrategy lets the recovery code of generated rule functions
+// pass subtreeReusedException through as though the rule had matched.
+type incrementalErrorStrategy struct {
+	ErrorStrategy
+
+	parser *BaseParser
+}
+
+func (s *incrementalErrorStrategy) Sync(recognizer Parser) {
+	s.parser.ruleHook.skipRule(s.parser)
+	s.ErrorStrategy.Sync(recognizer)
+}
+
+func (s *incrementalErrorStrategy) ReportError(recognizer Parser, e RecognitionException) {
+	if _, ok := e.(*subtreeReusedException); ok {
+		recognizer.GetParserRuleContext().SetException(nil)
+
+		return
+	}
+
+	s.ErrorStrategy.ReportError(recognizer, e)
+}
+
+func (s *incrementalErrorStrategy) Recover(recognizer Parser, e RecognitionException) {
+	if _, ok := e.(*subtreeReusedException); ok {
+		s.ErrorStrategy.ReportMatch(recognizer)
+
+		return
+	}
+
+	s.ErrorStrategy.Recover(recognizer, e)
+}
+
+// reachTokenStream records the index of the furthest token looked at.
+type reachTokenStream struct {
+	TokenStream
+
+	reach int
+}
+
+func (s *reachTokenStream) LT(k int) Token {
+	t := s.TokenStream.LT(k)
+
+	if k > 0 && t != nil {
+		s.reach = intMax(s.reach, t.GetTokenIndex())
+	}
+
+	return t
+}
+
+func (s *reachTokenStream) LA(i int) int {
+	if i > 0 {
+		return s.LT(i).GetTokenType()
+	}
+
+	return s.TokenStream.LA(i)
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// calcIncrementalParse keeps a Calc parse tree up to date with an
+// IncrementalLexer and an IncrementalParser.
+type calcIncrementalParse struct {
+	lexer  *IncrementalLexer
+	parser *CalcParser
+	ip     *IncrementalParser
+	tree   ParserRuleContext
+	reused int
+}
+
+func newCalcIncrementalParse(text string) *calcIncrementalParse {
+	c := &calcIncrementalParse{lexer: NewIncrementalLexer(NewCalcLexer(nil).BaseLexer, text)}
+
+	c.parser = NewCalcParser(c.lexer.GetTokenStream(TokenDefaultChannel))
+	c.parser.RemoveErrorListeners()
+	c.ip = NewIncrementalParser(c.parser.BaseParser)
+	c.parse()
+
+	return c
+}
+
+func (c *calcIncrementalParse) parse() {
+	c.tree = c.ip.Parse(c.lexer.GetTokenStream(TokenDefaultChannel), func() ParserRuleContext { return c.parser.Prog() })
+	c.reused += c.ip.GetReusedCount()
+}
+
+func (c *calcIncrementalParse) update(edits ...TextEdit) error {
+	if _, err := c.lexer.Update(edits...); err != nil {
+		return err
+	}
+
+	c.parse()
+
+	return nil
+}
+
+// describeCalcTree describes every node of tree in order: its type, tokens,
+// invoking state, the argument of expr contexts and the labels of stat
+// contexts as the children they refer to.
+func describeCalcTree(tree Tree) string {
+	var buf strings.Builder
+
+	tokenIndex := func(t Token) int {
+		if t == nil {
+			return -2
+		}
+
+		return t.GetTokenIndex()
+	}
+
+	for _, node := range TreesDescendants(tree.(ParseTree)) {
+		fmt.Fprintf(&buf, "%T", node)
+
+		switch n := node.(type) {
+		case TerminalNode:
+			fmt.Fprintf(&buf, " %d %d %q", tokenIndex(n.GetSymbol()), n.GetSymbol().GetTokenType(), n.GetText())
+
+		case ParserRuleContext:
+			fmt.Fprintf(&buf, " %d-%d invoked at %d", tokenIndex(n.GetStart()), tokenIndex(n.GetStop()), n.GetInvokingState())
+		}
+
+		switch n := node.(type) {
+		case *ExprContext:
+			fmt.Fprintf(&buf, " p %d", n.GetP())
+
+		case *StatContext:
+			fmt.Fprintf(&buf, " id %d", tokenIndex(n.GetId()))
+
+			if n.GetValue() != nil {
+				fmt.Fprintf(&buf, " value %d", n.indexOfChild(n.GetValue()))
+			}
+
+			for _, arg := range n.GetArgs() {
+				fmt.Fprintf(&buf, " arg %d", n.indexOfChild(arg))
+			}
+		}
+
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
+
+// checkCalcIncrementalParse compares the tree of c with the tree of a full
+// parse of the same text.
+func checkCalcIncrementalParse(t *testing.T, name string, c *calcIncrementalParse) {
+	p, tree := parseCalc(c.lexer.GetText())
+
+	if got, want := TreesStringTree(c.tree, nil, p), TreesStringTree(tree, nil, p); got != want {
+		t.Fatalf("%s: for %q got\n%s\nwant\n%s", name, c.lexer.GetText(), got, want)
+	}
+
+	if got, want := describeCalcTree(c.tree), describeCalcTree(tree); got != want {
+		t.Fatalf("%s: for %q got\n%s\nwant\n%s", name, c.lexer.GetText(), got, want)
+	}
+
+	checkCalcParents(t, name, c.tree)
+}
+
+func TestIncrementalParserReusesSubtrees(t *testing.T) {
+	text := "x = a + 1;\nprint a, b;\n{ y = \"s\" \"t\"; \"u\" : z; }\n"
+	c := newCalcIncrementalParse(text)
+
+	// Change the last statement: the first two are reused
+	if err := c.update(TextEdit{Offset: strings.Index(text, "z"), DeletedLength: 1, InsertedText: "w"}); err != nil {
+		t.Fatal(err)
+	}
+
+	checkCalcIncrementalParse(t, "edit", c)
+
+	if c.ip.GetReusedCount() == 0 {
+		t.Error("nothing reused")
+	}
+}
+
+func TestIncrementalParserRuleArguments(t *testing.T) {
+	// expr is called with the length of the identifier, which changes
+	// although the tokens of the expr do not
+	c := newCalcIncrementalParse("x = a + 1;\n")
+
+	if err := c.update(TextEdit{Offset: 0, DeletedLength: 1, InsertedText: "xyz"}); err != nil {
+		t.Fatal(err)
+	}
+
+	checkCalcIncrementalParse(t, "argument", c)
+
+	if p := c.tree.(*ProgContext).AllStat()[0].(*StatContext).GetValue().GetP(); p != 3 {
+		t.Errorf("got p %d, want 3", p)
+	}
+}
+
+func TestIncrementalParserInvokingStates(t *testing.T) {
+	tests := []struct {
+		text  string
+		edits []TextEdit
+	}{
+		// str is invoked from stat, then from text
+		{"\"s\" : y;", []TextEdit{{4, 1, "+"}}},
+		// and back again
+		{"\"s\" + y;", []TextEdit{{4, 1, ":"}}},
+		// stat is invoked from prog, then from block
+		{"y; z;", []TextEdit{{0, 0, "{ "}, {5, 0, " }"}}},
+		// and from stat
+		{"y; z;", []TextEdit{{0, 0, "\"s\" : "}}},
+		// expr is invoked from stat, then from atom
+		{"y + 1;", []TextEdit{{0, 0, "("}, {5, 0, ")"}}},
+	}
+
+	for _, test := range tests {
+		c := newCalcIncrementalParse(test.text)
+
+		if err := c.update(test.edits...); err != nil {
+			t.Fatal(err)
+		}
+
+		checkCalcIncrementalParse(t, test.text, c)
+	}
+}
+
+// calcParserEdits are the insertions of random edits for the parser tests,
+// made of whole tokens more often than not so that most of the text parses.
+var calcParserEdits = []string{
+	"x = 1;", "print a, b;", "\"s\" : ", "\"s\" \"t\"", "{", "}", "+ 2", "(", ")", ";", "y", "xyz = ", "\n", " ", ",", "\"",
+}
+
+func TestIncrementalParserMatchesFullParse(t *testing.T) {
+	r := rand.New(rand.NewSource(46))
+	reused := 0
+
+	for run := 0; run < 20; run++ {
+		text := []rune("x = a + 1;\nprint a, b;\n{ y = \"s\" \"t\"; \"u\" : z; }\nw = (1 + q);\n")
+		c := newCalcIncrementalParse(string(text))
+
+		for step := 0; step < 40; step++ {
+			var e TextEdit
+
+			if r.Intn(3) == 0 {
+				e = randomTextEdit(r, text)
+			} else {
+				e.Offset = r.Intn(len(text) + 1)
+				e.InsertedText = calcParserEdits[r.Intn(len(calcParserEdits))]
+
+				if r.Intn(2) == 0 && e.Offset < len(text) {
+					e.DeletedLength = 1 + r.Intn(intMin(len(text)-e.Offset, 6))
+				}
+			}
+
+			if len(text) > 300 {
+				e.InsertedText = ""
+			}
+
+			if err := c.update(e); err != nil {
+				t.Fatal(err)
+			}
+
+			text = applyTextEdits(text, []TextEdit{e})
+
+			checkCalcIncrementalParse(t, fmt.Sprintf("run %d step %d after %+v", run, step, e), c)
+		}
+
+		reused += c.reused
+	}
+
+	if reused == 0 {
+		t.Error("nothing reused")
+	}
+}
@@ -294,3 +294,41 @@ func (i *IntervalSet) elementName(literalNames []string, symbolicNames []string,
 		return symbolicNames[a]
 	}
 }
+
+// ToList returns the elements of the set in ascending order.
+func (i *IntervalSet) ToList() []int {
+	values := make([]int, 0, i.length())
+
+	for _, v := range i.intervals {
+		for j := v.start; j < v.stop; j++ {
+			values = append(values, j)
+		}
+	}
+
+	return values
+}
+
+// and returns the elements that are in both i and other.
+func (i *IntervalSet) and(other *IntervalSet) *IntervalSet {
+	result := NewIntervalSet()
+
+	for j, k := 0, 0; j < len(i.intervals) && k < len(other.intervals); {
+		a := i.intervals[j]
+		b := other.intervals[k]
+
+		start := intMax(a.start, b.start)
+		stop := intMin(a.stop, b.stop)
+
+		if start < stop {
+			result.addInterval(NewInterval(start, stop))
+		}
+
+		if a.stop < b.stop {
+			j++
+		} else {
+			k++
+		}
+	}
+
+	return result
+}
@@ -15,17 +15,33 @@ import (
 //  of speed.
 ///
 
+// Lexer is the interface lexer actions and CommonTokenStream use. A
+// hand-written lexer can implement it directly; lexers that run an ATN embed
+// BaseLexer instead.
 type Lexer interface {
 	TokenSource
 	Recognizer
 
+	// Emit creates the token for the text matched so far and makes it the
+	// token NextToken returns.
 	Emit() Token
 
-	setChannel(int)
-	pushMode(int)
-	popMode() int
-	setType(int)
-	setMode(int)
+	// SetChannel sets the channel of the token being matched.
+	SetChannel(int)
+
+	// PushMode saves the current mode on the mode stack and switches to the
+	// given mode.
+	PushMode(int)
+
+	// PopMode switches back to the mode on top of the mode stack and returns
+	// it.
+	PopMode() int
+
+	// SetType sets the type of the token being matched.
+	SetType(int)
+
+	// SetMode switches to the given mode.
+	SetMode(int)
 }
 
 type BaseLexer struct {
@@ -41,6 +57,7 @@ type BaseLexer struct {
 	input                  CharStream
 	factory                TokenFactory
 	tokenFactorySourcePair *TokenSourceCharStreamPair
+	errorStrategy          LexerErrorStrategy
 	token                  Token
 	hitEOF                 bool
 	channel                int
@@ -58,6 +75,7 @@ func NewBaseLexer(input CharStream) *BaseLexer {
 
 	lexer.input = input
 	lexer.factory = CommonTokenFactoryDEFAULT
+	lexer.errorStrategy = NewLexerSkipErrorStrategy()
 	lexer.tokenFactorySourcePair = &TokenSourceCharStreamPair{lexer, input}
 
 	lexer.Virt = lexer
@@ -150,7 +168,7 @@ func (b *BaseLexer) GetSourceName() string {
 	return b.GrammarFileName
 }
 
-func (b *BaseLexer) setChannel(v int) {
+func (b *BaseLexer) SetChannel(v int) {
 	b.channel = v
 }
 
@@ -158,17 +176,34 @@ func (b *BaseLexer) GetTokenFactory() TokenFactory {
 	return b.factory
 }
 
-func (b *BaseLexer) setTokenFactory(f TokenFactory) {
+func (b *BaseLexer) SetTokenFactory(f TokenFactory) {
 	b.factory = f
 }
 
+func (b *BaseLexer) GetErrorStrategy() LexerErrorStrategy {
+	return b.errorStrategy
+}
+
+// SetErrorStrategy sets what the lexer does with input no rule matches. The
+// default, LexerSkipErrorStrategy, drops it a char at a time.
+func (b *BaseLexer) SetErrorStrategy(s LexerErrorStrategy) {
+	b.errorStrategy = s
+}
+
+func (b *BaseLexer) GetTokenStartCharIndex() int {
+	return b.TokenStartCharIndex
+}
+
+func (b *BaseLexer) GetMode() int {
+	return b.mode
+}
+
 func (b *BaseLexer) safeMatch() (ret int) {
 	defer func() {
 		if e := recover(); e != nil {
 			if re, ok := e.(RecognitionException); ok {
 				b.notifyListeners(re) // Report error
-				b.Recover(re)
-				ret = LexerSkip // default
+				ret = b.errorStrategy.Recover(b, re)
 			}
 		}
 	}()
@@ -250,11 +285,11 @@ func (b *BaseLexer) More() {
 	b.thetype = LexerMore
 }
 
-func (b *BaseLexer) setMode(m int) {
+func (b *BaseLexer) SetMode(m int) {
 	b.mode = m
 }
 
-func (b *BaseLexer) pushMode(m int) {
+func (b *BaseLexer) PushMode(m int) {
 	if LexerATNSimulatorDebug {
 		fmt.Println("pushMode " + strconv.Itoa(m))
 	}
@@ -262,7 +297,7 @@ func (b *BaseLexer) pushMode(m int) {
 	b.mode = m
 }
 
-func (b *BaseLexer) popMode() int {
+func (b *BaseLexer) PopMode() int {
 	if len(b.modeStack) == 0 {
 		panic("Empty Stack")
 	}
@@ -278,7 +313,7 @@ func (b *BaseLexer) inputStream() CharStream {
 	return b.input
 }
 
-func (b *BaseLexer) setInputStream(input CharStream) {
+func (b *BaseLexer) SetInputStream(input CharStream) {
 	b.input = nil
 	b.tokenFactorySourcePair = &TokenSourceCharStreamPair{b, b.input}
 	b.reset()
@@ -331,7 +366,7 @@ func (b *BaseLexer) GetType() int {
 	return b.thetype
 }
 
-func (b *BaseLexer) setType(t int) {
+func (b *BaseLexer) SetType(t int) {
 	b.thetype = t
 }
 
@@ -358,10 +393,10 @@ func (b *BaseLexer) GetATN() *ATN {
 	return b.Interpreter.ATN()
 }
 
-// Return a list of all Token objects in input char stream.
-// Forces load of all tokens. Does not include EOF token.
-// /
-func (b *BaseLexer) getAllTokens() []Token {
+// GetAllTokens returns a list of all Token objects in input char stream.
+// Forces load of all tokens. Does not include EOF token. Use Tokenize to
+// filter the tokens or collect the errors.
+func (b *BaseLexer) GetAllTokens() []Token {
 	vl := b.Virt
 	tokens := make([]Token, 0)
 	t := vl.NextToken()
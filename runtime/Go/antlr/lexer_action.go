@@ -101,7 +101,7 @@ func NewLexerTypeAction(thetype int) *LexerTypeAction {
 }
 
 func (l *LexerTypeAction) execute(lexer Lexer) {
-	lexer.setType(l.thetype)
+	lexer.SetType(l.thetype)
 }
 
 func (l *LexerTypeAction) Hash() string {
@@ -142,7 +142,7 @@ func NewLexerPushModeAction(mode int) *LexerPushModeAction {
 // <p>This action is implemented by calling {@link Lexer//pushMode} with the
 // value provided by {@link //getMode}.</p>
 func (l *LexerPushModeAction) execute(lexer Lexer) {
-	lexer.pushMode(l.mode)
+	lexer.PushMode(l.mode)
 }
 
 func (l *LexerPushModeAction) Hash() string {
@@ -184,7 +184,7 @@ var LexerPopModeActionINSTANCE = NewLexerPopModeAction()
 
 // <p>This action is implemented by calling {@link Lexer//popMode}.</p>
 func (l *LexerPopModeAction) execute(lexer Lexer) {
-	lexer.popMode()
+	lexer.PopMode()
 }
 
 func (l *LexerPopModeAction) String() string {
@@ -236,7 +236,7 @@ func NewLexerModeAction(mode int) *LexerModeAction {
 // <p>This action is implemented by calling {@link Lexer//mode} with the
 // value provided by {@link //getMode}.</p>
 func (l *LexerModeAction) execute(lexer Lexer) {
-	lexer.setMode(l.mode)
+	lexer.SetMode(l.mode)
 }
 
 func (l *LexerModeAction) Hash() string {
@@ -328,7 +328,7 @@ func NewLexerChannelAction(channel int) *LexerChannelAction {
 // <p>This action is implemented by calling {@link Lexer//setChannel} with the
 // value provided by {@link //getChannel}.</p>
 func (l *LexerChannelAction) execute(lexer Lexer) {
-	lexer.setChannel(l.channel)
+	lexer.SetChannel(l.channel)
 }
 
 func (l *LexerChannelAction) Hash() string {
@@ -25,7 +25,9 @@ type ILexerATNSimulator interface {
 	reset()
 	Match(input CharStream, mode int) int
 	GetCharPositionInLine() int
+	SetCharPositionInLine(int)
 	GetLine() int
+	SetLine(int)
 	GetText(input CharStream) string
 	Consume(input CharStream)
 }
@@ -621,10 +623,18 @@ func (l *LexerATNSimulator) GetCharPositionInLine() int {
 	return l.CharPositionInLine
 }
 
+func (l *LexerATNSimulator) SetCharPositionInLine(charPositionInLine int) {
+	l.CharPositionInLine = charPositionInLine
+}
+
 func (l *LexerATNSimulator) GetLine() int {
 	return l.Line
 }
 
+func (l *LexerATNSimulator) SetLine(line int) {
+	l.Line = line
+}
+
 func (l *LexerATNSimulator) GetTokenName(tt int) string {
 	if tt == -1 {
 		return "EOF"
@@ -0,0 +1,204 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"strconv"
+	"sync"
+)
+
+// RecoverableLexer is the part of a lexer a LexerErrorStrategy works with.
+// BaseLexer implements it; a lexer that does not embed BaseLexer implements it
+// to use the strategies of this package.
+type RecoverableLexer interface {
+	Lexer
+
+	// Recover consumes one char of the input, unless at EOF, after the token
+	// recognition error e.
+	Recover(e RecognitionException)
+
+	// GetTokenStartCharIndex returns the index of the first char of the
+	// token being matched.
+	GetTokenStartCharIndex() int
+
+	// GetMode returns the current mode.
+	GetMode() int
+}
+
+var _ RecoverableLexer = &BaseLexer{}
+
+// LexerErrorStrategy decides what a lexer does with input that no lexer rule
+// matches. Set it with BaseLexer.SetErrorStrategy.
+type LexerErrorStrategy interface {
+	// Recover is called once the token recognition error e has been reported
+	// to the error listeners. The chars from lexer.GetTokenStartCharIndex()
+	// to the current index of the input are the ones no rule matched; Recover
+	// consumes the input that is to be dropped or turned into a token, at
+	// least one char unless at EOF, and returns the type of the token to emit
+	// for the text from the token start, or LexerSkip to emit none.
+	Recover(lexer RecoverableLexer, e RecognitionException) int
+}
+
+// LexerErrorStrategyFunc adapts a function to the LexerErrorStrategy
+// interface.
+type LexerErrorStrategyFunc func(lexer RecoverableLexer, e RecognitionException) int
+
+func (f LexerErrorStrategyFunc) Recover(lexer RecoverableLexer, e RecognitionException) int {
+	return f(lexer, e)
+}
+
+// LexerSkipErrorStrategy drops the unmatched chars, as lexers always have: it
+// calls the Recover method of the lexer, which consumes one char, and emits no
+// token.
+type LexerSkipErrorStrategy struct {
+}
+
+var _ LexerErrorStrategy = &LexerSkipErrorStrategy{}
+
+func NewLexerSkipErrorStrategy() *LexerSkipErrorStrategy {
+	return new(LexerSkipErrorStrategy)
+}
+
+func (s *LexerSkipErrorStrategy) Recover(lexer RecoverableLexer, e RecognitionException) int {
+	lexer.Recover(e)
+
+	return LexerSkip
+}
+
+// LexerErrorTokenStrategy turns the unmatched chars into error tokens, so that
+// every char of the input is covered by some token.
+type LexerErrorTokenStrategy struct {
+	tokenType int
+	channel   int
+	merge     bool
+
+	// The chars that can begin a token in each mode, by ATN
+	startCharsMu sync.Mutex
+	startChars   map[*ATN][]*IntervalSet
+}
+
+var _ LexerErrorStrategy = &LexerErrorTokenStrategy{}
+
+// NewLexerErrorTokenStrategy returns a strategy that emits tokens of type
+// tokenType on channel for the unmatched chars. The type must be one the
+// grammar defines for error tokens, such as ERROR_TOKEN declared in a tokens
+// block, so that the parser and Vocabulary know it; it panics for
+// TokenInvalidType and EOF. If merge is true, the chars that follow and cannot
+// begin any token in the current mode are added to the same error token rather
+// than each getting a token and an error report of its own.
+func NewLexerErrorTokenStrategy(tokenType, channel int, merge bool) *LexerErrorTokenStrategy {
+	if tokenType < TokenMinUserTokenType {
+		panic("Error token type " + strconv.Itoa(tokenType) + " is not a token type of the grammar")
+	}
+
+	return &LexerErrorTokenStrategy{
+		tokenType: tokenType,
+		channel:   channel,
+		merge:     merge,
+	}
+}
+
+func (s *LexerErrorTokenStrategy) Recover(lexer RecoverableLexer, e RecognitionException) int {
+	lexer.Recover(e)
+
+	if s.merge {
+		input := lexer.GetInputStream()
+		startChars := s.getStartChars(lexer.GetATN(), lexer.GetMode())
+
+		for c := input.LA(1); c != TokenEOF && !startChars.contains(c); c = input.LA(1) {
+			lexer.Recover(e)
+		}
+	}
+
+	if lexer.GetInputStream().Index() == lexer.GetTokenStartCharIndex() {
+		// Nothing to cover, at EOF
+		return LexerSkip
+	}
+
+	lexer.SetChannel(s.channel)
+
+	return s.tokenType
+}
+
+// getStartChars returns the chars that can begin a token in mode of atn,
+// computing them on first use.
+func (s *LexerErrorTokenStrategy) getStartChars(atn *ATN, mode int) *IntervalSet {
+	s.startCharsMu.Lock()
+	defer s.startCharsMu.Unlock()
+
+	if s.startChars == nil {
+		s.startChars = make(map[*ATN][]*IntervalSet)
+	}
+
+	sets, ok := s.startChars[atn]
+
+	if !ok {
+		sets = make([]*IntervalSet, len(atn.modeToStartState))
+		s.startChars[atn] = sets
+	}
+
+	if mode < 0 || mode >= len(sets) {
+		return NewIntervalSet()
+	}
+
+	if sets[mode] == nil {
+		sets[mode] = lexerStartChars(atn.modeToStartState[mode])
+	}
+
+	return sets[mode]
+}
+
+// LexerBailErrorStrategy stops lexing at the first unmatched char by
+// panicking with a ParseCancellationException, like BailErrorStrategy does
+// for parsers.
+type LexerBailErrorStrategy struct {
+}
+
+var _ LexerErrorStrategy = &LexerBailErrorStrategy{}
+
+func NewLexerBailErrorStrategy() *LexerBailErrorStrategy {
+	return new(LexerBailErrorStrategy)
+}
+
+func (s *LexerBailErrorStrategy) Recover(lexer RecoverableLexer, e RecognitionException) int {
+	panic(NewParseCancellationException())
+}
+
+// lexerStartChars returns the chars with which some lexer rule reachable from
+// the mode start state start can match a token. Predicates are assumed to be
+// true.
+func lexerStartChars(start ATNState) *IntervalSet {
+	chars := NewIntervalSet()
+	visited := make(map[int]bool)
+	stack := []ATNState{start}
+
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if visited[s.GetStateNumber()] {
+			continue
+		}
+
+		visited[s.GetStateNumber()] = true
+
+		for _, t := range s.GetTransitions() {
+			switch tt := t.(type) {
+			case *WildcardTransition:
+				chars.addRange(LexerMinCharValue, LexerMaxCharValue)
+			case *NotSetTransition:
+				chars.addSet(tt.getLabel().complement(LexerMinCharValue, LexerMaxCharValue))
+			default:
+				if t.getIsEpsilon() {
+					stack = append(stack, t.getTarget())
+				} else if label := t.getLabel(); label != nil {
+					chars.addSet(label)
+				}
+			}
+		}
+	}
+
+	return chars
+}
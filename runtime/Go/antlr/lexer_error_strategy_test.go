@@ -0,0 +1,221 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"strings"
+	"testing"
+)
+
+// calcErrorToken is a token type after those of Calc, for error tokens.
+const calcErrorToken = CalcLexerSTRING_END + 1
+
+// lexCalcWithStrategy lexes text with s and returns the tokens, including
+// EOF, and the token recognition errors.
+func lexCalcWithStrategy(text string, s LexerErrorStrategy) ([]Token, []string) {
+	lexer := NewCalcLexer(NewInputStream(text))
+	errors := &calcErrorRecorder{DefaultErrorListener: NewDefaultErrorListener()}
+
+	lexer.RemoveErrorListeners()
+	lexer.AddErrorListener(errors)
+	lexer.SetErrorStrategy(s)
+
+	tokens := make([]Token, 0)
+
+	for {
+		t := lexer.NextToken()
+		tokens = append(tokens, t)
+
+		if t.GetTokenType() == TokenEOF {
+			return tokens, errors.errors
+		}
+	}
+}
+
+// describeErrorTokens returns the text of the tokens, with error tokens in
+// brackets.
+func describeErrorTokens(tokens []Token) string {
+	texts := make([]string, 0, len(tokens))
+
+	for _, t := range tokens {
+		switch t.GetTokenType() {
+		case calcErrorToken:
+			texts = append(texts, "["+t.GetText()+"]")
+		case TokenEOF:
+			texts = append(texts, "EOF")
+		default:
+			texts = append(texts, t.GetText())
+		}
+	}
+
+	return strings.Join(texts, "|")
+}
+
+func TestLexerErrorTokenStrategy(t *testing.T) {
+	tests := []struct {
+		text   string
+		merge  bool
+		want   string
+		errors int
+	}{
+		{"x @@ ;", false, "x| |[@]|[@]| |;|EOF", 2},
+		{"x @@ ;", true, "x| |[@@]| |;|EOF", 1},
+		{"@@x", false, "[@]|[@]|x|EOF", 2},
+		{"@@x", true, "[@@]|x|EOF", 1},
+
+		// '#' can begin a token, so it is not merged even though it fails
+		{"@#", true, "[@]|[#]|EOF", 2},
+
+		// Newlines are white space in the default mode but cannot begin a
+		// token in the string mode
+		{"@\n\n", true, "[@]|\n\n|EOF", 1},
+		{"\"a\n\nb\"", false, "\"|a|[\n]|[\n]|b|\"|EOF", 2},
+		{"\"a\n\nb\"", true, "\"|a|[\n\n]|b|\"|EOF", 1},
+
+		// An error at EOF covers the chars up to it
+		{"x = #inc", false, "x| |=| |[#inc]|EOF", 1},
+		{"x = #inc", true, "x| |=| |[#inc]|EOF", 1},
+	}
+
+	for _, test := range tests {
+		tokens, errors := lexCalcWithStrategy(test.text, NewLexerErrorTokenStrategy(calcErrorToken, TokenHiddenChannel, test.merge))
+
+		if got := describeErrorTokens(tokens); got != test.want {
+			t.Errorf("%q merge %v: got %q, want %q", test.text, test.merge, got, test.want)
+		}
+
+		if len(errors) != test.errors {
+			t.Errorf("%q merge %v: got errors %q, want %d", test.text, test.merge, errors, test.errors)
+		}
+
+		// Every char is covered by a token, and error tokens are hidden
+		text := ""
+
+		for _, tok := range tokens {
+			if tok.GetTokenType() == TokenEOF {
+				continue
+			}
+
+			if tok.GetText() != test.text[tok.GetStart():tok.GetStop()+1] {
+				t.Errorf("%q merge %v: token %q at %d-%d", test.text, test.merge, tok.GetText(), tok.GetStart(), tok.GetStop())
+			}
+
+			if tok.GetTokenType() == calcErrorToken && tok.GetChannel() != TokenHiddenChannel {
+				t.Errorf("%q merge %v: error token on channel %d", test.text, test.merge, tok.GetChannel())
+			}
+
+			text += tok.GetText()
+		}
+
+		if text != test.text {
+			t.Errorf("%q merge %v: tokens cover %q", test.text, test.merge, text)
+		}
+	}
+}
+
+func TestLexerErrorTokenStrategyInvalidType(t *testing.T) {
+	for _, ttype := range []int{TokenInvalidType, TokenEOF} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("no panic for token type %d", ttype)
+				}
+			}()
+
+			NewLexerErrorTokenStrategy(ttype, TokenDefaultChannel, false)
+		}()
+	}
+}
+
+func TestLexerErrorTokenStrategyStartChars(t *testing.T) {
+	s := NewLexerErrorTokenStrategy(calcErrorToken, TokenDefaultChannel, true)
+	chars := s.getStartChars(calcLexerATN, 0)
+
+	for _, c := range " \n=;\"#pxz0" {
+		if !chars.contains(int(c)) {
+			t.Errorf("%q cannot start a token", c)
+		}
+	}
+
+	for _, c := range "@$A" {
+		if chars.contains(int(c)) {
+			t.Errorf("%q can start a token", c)
+		}
+	}
+
+	if s.getStartChars(calcLexerATN, 0) != chars {
+		t.Error("start chars of the default mode computed twice")
+	}
+
+	if str := s.getStartChars(calcLexerATN, 1); str.contains('\n') || !str.contains('"') || !str.contains('@') {
+		t.Errorf("got start chars %s in the string mode", str)
+	}
+
+	if s.getStartChars(calcLexerATN, 2).length() != 0 {
+		t.Error("start chars of an undefined mode")
+	}
+}
+
+func TestLexerSkipErrorStrategy(t *testing.T) {
+	lexer := NewCalcLexer(nil)
+
+	if _, ok := lexer.GetErrorStrategy().(*LexerSkipErrorStrategy); !ok {
+		t.Errorf("got default strategy %T", lexer.GetErrorStrategy())
+	}
+
+	tokens, errors := lexCalcWithStrategy("x @@ ;", NewLexerSkipErrorStrategy())
+
+	if got, want := describeErrorTokens(tokens), "x| | |;|EOF"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if len(errors) != 2 {
+		t.Errorf("got errors %q", errors)
+	}
+}
+
+func TestLexerBailErrorStrategy(t *testing.T) {
+	lexer := NewCalcLexer(NewInputStream("x @ ;"))
+
+	lexer.RemoveErrorListeners()
+	lexer.SetErrorStrategy(NewLexerBailErrorStrategy())
+
+	if got := lexer.NextToken().GetText(); got != "x" {
+		t.Fatalf("got first token %q", got)
+	}
+
+	lexer.NextToken()
+
+	defer func() {
+		if _, ok := recover().(*ParseCancellationException); !ok {
+			t.Error("no ParseCancellationException")
+		}
+	}()
+
+	lexer.NextToken()
+}
+
+func TestLexerErrorStrategyFunc(t *testing.T) {
+	starts := make([]int, 0)
+
+	tokens, _ := lexCalcWithStrategy("x @@ ;", LexerErrorStrategyFunc(func(lexer RecoverableLexer, e RecognitionException) int {
+		starts = append(starts, lexer.GetTokenStartCharIndex())
+
+		// Drop the rest of the input
+		for lexer.GetInputStream().LA(1) != TokenEOF {
+			lexer.Recover(e)
+		}
+
+		return LexerSkip
+	}))
+
+	if got, want := describeErrorTokens(tokens), "x| |EOF"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if len(starts) != 1 || starts[0] != 2 {
+		t.Errorf("got error starts %v", starts)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// LexerState is the state of a BaseLexer between two tokens: everything that
+// determines how the rest of the input is lexed. It lets an editor re-lex from
+// the start of a changed line rather than from the start of the input.
+//
+// LexerState is a value type: two states are the same state if they are equal
+// with ==, and they can be used as map keys.
+type LexerState struct {
+	CharIndex int // The index in the input of the next char to lex
+	Line      int
+	Column    int
+	Mode      int
+	HitEOF    bool
+
+	// The mode stack, bottom first, as decimal numbers each followed by a
+	// comma, so that LexerState stays comparable
+	modeStack string
+}
+
+// GetModeStack returns the modes pushed with PushMode, bottom first.
+func (s LexerState) GetModeStack() []int {
+	modes := make([]int, 0)
+
+	for _, m := range strings.Split(strings.TrimSuffix(s.modeStack, ","), ",") {
+		if m == "" {
+			continue
+		}
+
+		mode, _ := strconv.Atoi(m)
+		modes = append(modes, mode)
+	}
+
+	return modes
+}
+
+// EqualsModes returns true if s and other have the same mode and mode stack.
+// Two states at different positions that are equal in this sense lex the
+// same text into the same tokens, which is how re-lexing after an edit knows
+// it has caught up with the old tokens.
+func (s LexerState) EqualsModes(other LexerState) bool {
+	return s.Mode == other.Mode && s.modeStack == other.modeStack
+}
+
+// Snapshot returns the state of the lexer. It must be called between tokens,
+// not from a lexer action.
+func (b *BaseLexer) Snapshot() LexerState {
+	modeStack := make([]byte, 0)
+
+	for _, m := range b.modeStack {
+		modeStack = strconv.AppendInt(modeStack, int64(m), 10)
+		modeStack = append(modeStack, ',')
+	}
+
+	return LexerState{
+		CharIndex: b.input.Index(),
+		Line:      b.Interpreter.GetLine(),
+		Column:    b.Interpreter.GetCharPositionInLine(),
+		Mode:      b.mode,
+		HitEOF:    b.hitEOF,
+		modeStack: string(modeStack),
+	}
+}
+
+// Restore puts the lexer back into state s, seeking the input to
+// s.CharIndex, so that the next call to NextToken returns the token that
+// follows s.
+func (b *BaseLexer) Restore(s LexerState) {
+	b.input.Seek(s.CharIndex)
+
+	b.Interpreter.SetLine(s.Line)
+	b.Interpreter.SetCharPositionInLine(s.Column)
+
+	b.mode = s.Mode
+	b.modeStack = s.GetModeStack()
+	b.hitEOF = s.HitEOF
+
+	b.token = nil
+	b.thetype = TokenInvalidType
+	b.channel = TokenDefaultChannel
+	b.text = ""
+}
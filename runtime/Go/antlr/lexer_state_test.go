@@ -0,0 +1,112 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLexerStateIsComparable(t *testing.T) {
+	text := "x \"abc\" y"
+	a := NewCalcLexer(NewInputStream(text))
+	b := NewCalcLexer(NewInputStream(text))
+
+	states := make(map[LexerState]int)
+
+	for i := 0; ; i++ {
+		sa, sb := a.Snapshot(), b.Snapshot()
+
+		if sa != sb {
+			t.Fatalf("token %d: states %+v and %+v differ", i, sa, sb)
+		}
+
+		if _, ok := states[sa]; ok {
+			t.Fatalf("token %d: state %+v seen before", i, sa)
+		}
+
+		states[sa] = i
+
+		if a.NextToken().GetTokenType() == TokenEOF {
+			break
+		}
+
+		b.NextToken()
+	}
+
+	// x, WS, QUOTE, STRING_TEXT, STRING_END, WS, y, EOF
+	if len(states) != 8 {
+		t.Errorf("got %d states, want 8", len(states))
+	}
+}
+
+func TestLexerStateModeStack(t *testing.T) {
+	l := NewCalcLexer(NewInputStream("\"abc\""))
+	before := l.Snapshot()
+
+	l.NextToken()
+
+	inString := l.Snapshot()
+
+	if got := fmt.Sprint(inString.GetModeStack()); got != "[0]" || inString.Mode != CalcLexerSTR {
+		t.Errorf("got mode %d and stack %s, want mode %d and stack [0]", inString.Mode, got, CalcLexerSTR)
+	}
+
+	if before.EqualsModes(inString) {
+		t.Error("default mode equals string mode")
+	}
+
+	pushed := inString
+
+	l.PushMode(3)
+	l.PushMode(12)
+
+	if got := fmt.Sprint(l.Snapshot().GetModeStack()); got != "[0 1 3]" {
+		t.Errorf("got stack %s, want [0 1 3]", got)
+	}
+
+	if l.Snapshot() == pushed {
+		t.Error("states with different mode stacks are equal")
+	}
+}
+
+func TestLexerStateRestore(t *testing.T) {
+	l := NewCalcLexer(NewInputStream("a\n\"b c\" d"))
+
+	l.NextToken()
+	l.NextToken()
+	l.NextToken()
+
+	s := l.Snapshot()
+	first := tokenDescriptions(l.GetAllTokens())
+
+	if !l.Snapshot().HitEOF {
+		t.Error("state at the end has not hit EOF")
+	}
+
+	l.Restore(s)
+
+	if l.Snapshot() != s {
+		t.Errorf("restored state %+v, want %+v", l.Snapshot(), s)
+	}
+
+	if second := tokenDescriptions(l.GetAllTokens()); second != first {
+		t.Errorf("after restoring got\n%s\nwant\n%s", second, first)
+	}
+}
+
+// tokenDescriptions describes the type, position, channel and text of each
+// token, one per line.
+func tokenDescriptions(tokens []Token) string {
+	desc := ""
+
+	for _, t := range tokens {
+		desc += fmt.Sprintf("%d type %d %d-%d line %d:%d channel %d %q\n",
+			t.GetTokenIndex(), t.GetTokenType(), t.GetStart(), t.GetStop(),
+			t.GetLine(), t.GetColumn(), t.GetChannel(), t.GetText())
+	}
+
+	return desc
+}
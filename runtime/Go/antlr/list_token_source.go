@@ -0,0 +1,180 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+// ListTokenSource is a TokenSource that returns the tokens of a list, such as
+// tokens read from a cache or made by a preprocessor. If the list does not end
+// with an EOF token, one is created just after the last token, as a lexer
+// would emit it at the end of the text.
+type ListTokenSource struct {
+	tokens     []Token
+	index      int
+	eof        Token
+	sourceName string
+	factory    TokenFactory
+}
+
+var _ TokenSource = &ListTokenSource{}
+
+// NewListTokenSource returns a source of tokens. The source name may be "", in
+// which case the name of the input of the tokens is used.
+func NewListTokenSource(tokens []Token, sourceName string) *ListTokenSource {
+	return &ListTokenSource{
+		tokens:     tokens,
+		sourceName: sourceName,
+		factory:    CommonTokenFactoryDEFAULT,
+	}
+}
+
+// GetTokens returns the tokens of the list.
+func (s *ListTokenSource) GetTokens() []Token {
+	return s.tokens
+}
+
+func (s *ListTokenSource) NextToken() Token {
+	if s.index >= len(s.tokens) {
+		if s.eof == nil {
+			source := &TokenSourceCharStreamPair{s, s.GetInputStream()}
+			s.eof = newEOFTokenAfter(s.factory, source, s.tokens)
+		}
+
+		return s.eof
+	}
+
+	t := s.tokens[s.index]
+
+	if s.index == len(s.tokens)-1 && t.GetTokenType() == TokenEOF {
+		s.eof = t
+	}
+
+	s.index++
+
+	return t
+}
+
+func (s *ListTokenSource) Skip() {
+}
+
+func (s *ListTokenSource) More() {
+}
+
+// GetLine returns the line of the next token.
+func (s *ListTokenSource) GetLine() int {
+	if s.index < len(s.tokens) {
+		return s.tokens[s.index].GetLine()
+	}
+
+	if s.eof != nil {
+		return s.eof.GetLine()
+	}
+
+	_, line, _ := getEOFPosition(s.tokens)
+
+	return line
+}
+
+// GetCharPositionInLine returns the column of the next token.
+func (s *ListTokenSource) GetCharPositionInLine() int {
+	if s.index < len(s.tokens) {
+		return s.tokens[s.index].GetColumn()
+	}
+
+	if s.eof != nil {
+		return s.eof.GetColumn()
+	}
+
+	_, _, column := getEOFPosition(s.tokens)
+
+	return column
+}
+
+// GetInputStream returns the input of the next token, or of the last token
+// once there are no more.
+func (s *ListTokenSource) GetInputStream() CharStream {
+	if s.index < len(s.tokens) {
+		return s.tokens[s.index].GetInputStream()
+	}
+
+	if s.eof != nil {
+		return s.eof.GetInputStream()
+	}
+
+	if len(s.tokens) > 0 {
+		return s.tokens[len(s.tokens)-1].GetInputStream()
+	}
+
+	return nil
+}
+
+// GetSourceName returns the source name the ListTokenSource was made with or,
+// if there was none, the name of its input, or "List" if it has none.
+func (s *ListTokenSource) GetSourceName() string {
+	if s.sourceName != "" {
+		return s.sourceName
+	}
+
+	if input := s.GetInputStream(); input != nil {
+		return input.GetSourceName()
+	}
+
+	return "List"
+}
+
+func (s *ListTokenSource) SetTokenFactory(factory TokenFactory) {
+	s.factory = factory
+}
+
+func (s *ListTokenSource) GetTokenFactory() TokenFactory {
+	return s.factory
+}
+
+// newEOFTokenAfter creates an EOF token placed just after the last of tokens.
+func newEOFTokenAfter(factory TokenFactory, source *TokenSourceCharStreamPair, tokens []Token) Token {
+	start, line, column := getEOFPosition(tokens)
+	t := factory.Create(source, TokenEOF, "", TokenDefaultChannel, start, start-1, line, column)
+
+	if source.charStream == nil {
+		// Without an input, CommonToken cannot tell it is at the end
+		t.SetText("<EOF>")
+	}
+
+	t.SetTokenIndex(len(tokens))
+
+	return t
+}
+
+// getEOFPosition returns the char index, line and column just after the last
+// of tokens that has a position.
+func getEOFPosition(tokens []Token) (int, int, int) {
+	for i := len(tokens) - 1; i >= 0; i-- {
+		last := tokens[i]
+
+		if last.GetStop() < 0 {
+			// Conjured up, without a position
+			continue
+		}
+
+		line, column := last.GetLine(), last.GetColumn()
+		text := last.GetText()
+
+		if text == "" {
+			// No input to read the text from
+			column += last.GetStop() - last.GetStart() + 1
+		}
+
+		for _, r := range text {
+			if r == '\n' {
+				line++
+				column = 0
+			} else {
+				column++
+			}
+		}
+
+		return last.GetStop() + 1, line, column
+	}
+
+	return 0, 1, 0
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"testing"
+)
+
+func TestListTokenSourceWithoutEOF(t *testing.T) {
+	text := "x = 1;\nprint x;"
+	tokens := lexCalcTokens(text)
+	tokens = tokens[:len(tokens)-1]
+	source := NewListTokenSource(tokens, "")
+
+	for i, want := range tokens {
+		if source.GetLine() != want.GetLine() || source.GetCharPositionInLine() != want.GetColumn() {
+			t.Errorf("token %d: got position %d:%d, want %d:%d", i, source.GetLine(), source.GetCharPositionInLine(), want.GetLine(), want.GetColumn())
+		}
+
+		if got := source.NextToken(); got != want {
+			t.Fatalf("token %d: got %v, want %v", i, got, want)
+		}
+	}
+
+	// The EOF token follows the last ';', at 2:8
+	if source.GetLine() != 2 || source.GetCharPositionInLine() != 8 {
+		t.Errorf("got EOF position %d:%d, want 2:8", source.GetLine(), source.GetCharPositionInLine())
+	}
+
+	eof := source.NextToken()
+
+	if eof.GetTokenType() != TokenEOF || eof.GetStart() != len(text) || eof.GetStop() != len(text)-1 || eof.GetLine() != 2 || eof.GetColumn() != 8 || eof.GetTokenIndex() != len(tokens) {
+		t.Errorf("got EOF %v", eof)
+	}
+
+	if eof.GetText() != "<EOF>" || eof.GetInputStream() != tokens[0].GetInputStream() || eof.GetTokenSource() != source {
+		t.Errorf("got EOF text %q, input %v and source %v", eof.GetText(), eof.GetInputStream(), eof.GetTokenSource())
+	}
+
+	if source.NextToken() != eof || source.GetLine() != 2 || source.GetCharPositionInLine() != 8 {
+		t.Error("EOF not repeated")
+	}
+
+	if got := source.GetSourceName(); got != tokens[0].GetInputStream().GetSourceName() {
+		t.Errorf("got source name %q", got)
+	}
+}
+
+func TestListTokenSourceWithEOF(t *testing.T) {
+	tokens := lexCalcTokens("x = 1;\n")
+	source := NewListTokenSource(tokens, "calc")
+
+	for _, want := range tokens {
+		if got := source.NextToken(); got != want {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+
+	// The last token of the list is the EOF token
+	if source.NextToken() != tokens[len(tokens)-1] {
+		t.Error("EOF of the list not repeated")
+	}
+
+	if got := source.GetSourceName(); got != "calc" {
+		t.Errorf("got source name %q", got)
+	}
+}
+
+func TestListTokenSourceEmpty(t *testing.T) {
+	source := NewListTokenSource(nil, "")
+
+	if source.GetLine() != 1 || source.GetCharPositionInLine() != 0 || source.GetInputStream() != nil {
+		t.Errorf("got position %d:%d and input %v", source.GetLine(), source.GetCharPositionInLine(), source.GetInputStream())
+	}
+
+	eof := source.NextToken()
+
+	if eof.GetTokenType() != TokenEOF || eof.GetStart() != 0 || eof.GetLine() != 1 || eof.GetColumn() != 0 || eof.GetTokenIndex() != 0 || eof.GetText() != "<EOF>" {
+		t.Errorf("got EOF %v", eof)
+	}
+
+	if source.NextToken() != eof {
+		t.Error("EOF not repeated")
+	}
+
+	if got := source.GetSourceName(); got != "List" {
+		t.Errorf("got source name %q", got)
+	}
+}
+
+func TestListTokenSourceParse(t *testing.T) {
+	text := "x = 1;\n{ print x, \"s\"; }"
+	tokens := lexCalcTokens(text)
+
+	// Without the EOF token, which the source adds back
+	source := NewListTokenSource(tokens[:len(tokens)-1], "")
+	p := NewCalcParser(NewCommonTokenStream(source, TokenDefaultChannel))
+	errors := &calcErrorRecorder{DefaultErrorListener: NewDefaultErrorListener()}
+
+	p.RemoveErrorListeners()
+	p.AddErrorListener(errors)
+
+	got := p.Prog().ToStringTree(nil, p)
+	lexed, tree := parseCalc(text)
+
+	if want := tree.ToStringTree(nil, lexed); got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+
+	if len(errors.errors) != 0 {
+		t.Errorf("got errors %q", errors.errors)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+// LL1Analysis holds the FIRST and FOLLOW sets of every rule of a parser ATN and
+// the LL(1) lookahead sets of every decision, computed with LL1Analyzer.
+// Semantic predicates are treated as true.
+type LL1Analysis struct {
+	atn       *ATN
+	analyzer  *LL1Analyzer
+	first     []*IntervalSet
+	follow    []*IntervalSet
+	decisions []*DecisionLookahead
+}
+
+// DecisionLookahead holds the LL(1) lookahead of each alternative of a
+// decision. Alts[i] is the lookahead set of alternative i+1.
+type DecisionLookahead struct {
+	Decision    int
+	RuleIndex   int
+	StateNumber int
+	Alts        []*IntervalSet
+	Conflicts   []*LookaheadConflict
+}
+
+// LookaheadConflict lists the tokens that predict both Alt1 and Alt2 of a
+// decision, which therefore cannot be decided with one token of lookahead.
+type LookaheadConflict struct {
+	Alt1   int
+	Alt2   int
+	Tokens *IntervalSet
+}
+
+// NewLL1Analysis analyzes atn. Rules that are not invoked by any other rule are
+// treated as start rules, which are followed by TokenEOF.
+func NewLL1Analysis(atn *ATN) *LL1Analysis {
+	a := &LL1Analysis{
+		atn:      atn,
+		analyzer: NewLL1Analyzer(atn),
+	}
+
+	a.computeFirstSets()
+	a.computeFollowSets()
+	a.computeDecisions()
+
+	return a
+}
+
+// GetFirstSet returns the tokens that can begin rule ruleIndex. The set
+// contains TokenEpsilon if the rule can match the empty input.
+func (a *LL1Analysis) GetFirstSet(ruleIndex int) *IntervalSet {
+	return a.first[ruleIndex]
+}
+
+// GetFollowSet returns the tokens that can follow rule ruleIndex in any
+// context.
+func (a *LL1Analysis) GetFollowSet(ruleIndex int) *IntervalSet {
+	return a.follow[ruleIndex]
+}
+
+// GetDecisionLookahead returns the lookahead of decision, which is an index
+// into ATN.DecisionToState.
+func (a *LL1Analysis) GetDecisionLookahead(decision int) *DecisionLookahead {
+	return a.decisions[decision]
+}
+
+// GetDecisions returns the lookahead of every decision, indexed by decision
+// number.
+func (a *LL1Analysis) GetDecisions() []*DecisionLookahead {
+	return a.decisions
+}
+
+// GetNonLL1Decisions returns the decisions with at least one LookaheadConflict.
+func (a *LL1Analysis) GetNonLL1Decisions() []*DecisionLookahead {
+	result := make([]*DecisionLookahead, 0)
+
+	for _, d := range a.decisions {
+		if !d.IsLL1() {
+			result = append(result, d)
+		}
+	}
+
+	return result
+}
+
+func (a *LL1Analysis) computeFirstSets() {
+	a.first = make([]*IntervalSet, len(a.atn.ruleToStartState))
+
+	for i, s := range a.atn.ruleToStartState {
+		a.first[i] = a.analyzer.Look(s, nil, nil)
+	}
+}
+
+// computeFollowSets propagates, until nothing changes, the tokens after each
+// rule invocation to the invoked rule, and the FOLLOW set of the invoking rule
+// when the rest of the invoking rule can be empty.
+func (a *LL1Analysis) computeFollowSets() {
+	type invocation struct {
+		rule   int
+		caller int
+		look   *IntervalSet
+	}
+
+	invocations := make([]invocation, 0)
+	invoked := make([]bool, len(a.atn.ruleToStartState))
+
+	for _, s := range a.atn.states {
+		if s == nil {
+			continue
+		}
+
+		for _, t := range s.GetTransitions() {
+			if rt, ok := t.(*RuleTransition); ok {
+				rule := rt.getTarget().GetRuleIndex()
+
+				invocations = append(invocations, invocation{rule, s.GetRuleIndex(), a.analyzer.Look(rt.followState, nil, nil)})
+
+				if rule != s.GetRuleIndex() {
+					invoked[rule] = true
+				}
+			}
+		}
+	}
+
+	a.follow = make([]*IntervalSet, len(a.atn.ruleToStartState))
+
+	for i := range a.follow {
+		a.follow[i] = NewIntervalSet()
+
+		if !invoked[i] {
+			a.follow[i].addOne(TokenEOF)
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, inv := range invocations {
+			follow := a.follow[inv.rule]
+			n := follow.length()
+
+			follow.addSet(inv.look)
+
+			if inv.look.contains(TokenEpsilon) {
+				follow.removeOne(TokenEpsilon)
+				follow.addSet(a.follow[inv.caller])
+			}
+
+			if follow.length() != n {
+				changed = true
+			}
+		}
+	}
+}
+
+func (a *LL1Analysis) computeDecisions() {
+	a.decisions = make([]*DecisionLookahead, len(a.atn.DecisionToState))
+
+	for i, s := range a.atn.DecisionToState {
+		d := &DecisionLookahead{
+			Decision:    i,
+			RuleIndex:   s.GetRuleIndex(),
+			StateNumber: s.GetStateNumber(),
+			Alts:        make([]*IntervalSet, len(s.GetTransitions())),
+			Conflicts:   make([]*LookaheadConflict, 0),
+		}
+
+		for alt, t := range s.GetTransitions() {
+			look := a.analyzer.Look(t.getTarget(), nil, nil)
+
+			// Reaching the end of the rule means the alternative is followed
+			// by whatever follows the rule
+			if look.contains(TokenEpsilon) {
+				look.removeOne(TokenEpsilon)
+
+				if d.RuleIndex >= 0 {
+					look.addSet(a.follow[d.RuleIndex])
+				}
+			}
+
+			d.Alts[alt] = look
+		}
+
+		for j := 0; j < len(d.Alts); j++ {
+			for k := j + 1; k < len(d.Alts); k++ {
+				overlap := d.Alts[j].and(d.Alts[k])
+
+				if overlap.length() > 0 {
+					d.Conflicts = append(d.Conflicts, &LookaheadConflict{Alt1: j + 1, Alt2: k + 1, Tokens: overlap})
+				}
+			}
+		}
+
+		a.decisions[i] = d
+	}
+}
+
+// IsLL1 returns true if one token of lookahead is enough to choose between the
+// alternatives of the decision.
+func (d *DecisionLookahead) IsLL1() bool {
+	return len(d.Conflicts) == 0
+}
+
+// GetOverlap returns the tokens that predict more than one alternative.
+func (d *DecisionLookahead) GetOverlap() *IntervalSet {
+	overlap := NewIntervalSet()
+
+	for _, c := range d.Conflicts {
+		overlap.addSet(c.Tokens)
+	}
+
+	return overlap
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func calcTokenSet(s *IntervalSet) string {
+	return s.StringVerbose(calcLiteralNames, calcSymbolicNames, false)
+}
+
+func TestLL1AnalysisFirstAndFollowSets(t *testing.T) {
+	tests := []struct {
+		rule          int
+		first, follow string
+	}{
+		// prog is not invoked, so it is followed by EOF; stat* may be empty
+		{CalcParserRULE_prog, "{<EOF>, 'print', '(', '{', ID, INT, QUOTE}", "<EOF>"},
+		// stat is followed by the next stat, the end of prog or block, and
+		// by whatever follows the stat of str ':' stat
+		{CalcParserRULE_stat, "{'print', '(', '{', ID, INT, QUOTE}", "{<EOF>, 'print', '(', '{', '}', ID, INT, QUOTE}"},
+		{CalcParserRULE_block, "'{'", "{<EOF>, 'print', '(', '{', '}', ID, INT, QUOTE}"},
+		{CalcParserRULE_expr, "{'(', ID, INT, QUOTE}", "{';', ',', ')'}"},
+		// atom is followed by '+' within expr, and by what follows expr
+		{CalcParserRULE_atom, "{'(', ID, INT, QUOTE}", "{';', ',', '+', ')'}"},
+		{CalcParserRULE_text, "QUOTE", "{';', ',', '+', ')'}"},
+		// str is followed by the next str of text or by ':' in stat
+		{CalcParserRULE_str, "QUOTE", "{';', ',', '+', ')', ':', QUOTE}"},
+	}
+
+	a := NewLL1Analysis(calcParserATN)
+
+	for _, test := range tests {
+		if got := calcTokenSet(a.GetFirstSet(test.rule)); got != test.first {
+			t.Errorf("FIRST(%s): got %s, want %s", calcRuleNames[test.rule], got, test.first)
+		}
+
+		if got := calcTokenSet(a.GetFollowSet(test.rule)); got != test.follow {
+			t.Errorf("FOLLOW(%s): got %s, want %s", calcRuleNames[test.rule], got, test.follow)
+		}
+	}
+}
+
+func TestLL1AnalysisDecisions(t *testing.T) {
+	want := []string{
+		"prog: {'print', '(', '{', ID, INT, QUOTE}",
+		"prog: {'print', '(', '{', ID, INT, QUOTE} | <EOF>",
+		"stat: ','",
+		"stat: ',' | ';'",
+		"stat: ID | 'print' | '{' | QUOTE | {'(', ID, INT, QUOTE}",
+		"block: {'print', '(', '{', ID, INT, QUOTE}",
+		"block: {'print', '(', '{', ID, INT, QUOTE} | '}'",
+		"expr: '+'",
+		"expr: '+' | {';', ',', ')'}",
+		"atom: ID | INT | QUOTE | '('",
+		"text: QUOTE",
+		"text: QUOTE | {';', ',', '+', ')'}",
+		"str: STRING_TEXT | STRING_END",
+	}
+
+	a := NewLL1Analysis(calcParserATN)
+	decisions := a.GetDecisions()
+
+	if len(decisions) != len(want) {
+		t.Fatalf("got %d decisions, want %d", len(decisions), len(want))
+	}
+
+	for i, d := range decisions {
+		alts := make([]string, len(d.Alts))
+
+		for j, alt := range d.Alts {
+			alts[j] = calcTokenSet(alt)
+		}
+
+		if got := calcRuleNames[d.RuleIndex] + ": " + strings.Join(alts, " | "); got != want[i] {
+			t.Errorf("decision %d: got %s, want %s", i, got, want[i])
+		}
+
+		if d.Decision != i || d.StateNumber != calcParserATN.DecisionToState[i].GetStateNumber() || a.GetDecisionLookahead(i) != d {
+			t.Errorf("decision %d: got decision %d at state %d", i, d.Decision, d.StateNumber)
+		}
+	}
+}
+
+func TestLL1AnalysisNonLL1Decisions(t *testing.T) {
+	a := NewLL1Analysis(calcParserATN)
+	nonLL1 := a.GetNonLL1Decisions()
+
+	// ID '=' expr and str ':' stat both start like expr ';'
+	if len(nonLL1) != 1 || nonLL1[0].Decision != 4 || nonLL1[0].RuleIndex != CalcParserRULE_stat {
+		t.Fatalf("got %d non-LL(1) decisions, want decision 4 of stat", len(nonLL1))
+	}
+
+	d := nonLL1[0]
+	conflicts := make([]string, len(d.Conflicts))
+
+	for i, c := range d.Conflicts {
+		conflicts[i] = fmt.Sprintf("%d/%d %s", c.Alt1, c.Alt2, calcTokenSet(c.Tokens))
+	}
+
+	if got, want := strings.Join(conflicts, ", "), "1/5 ID, 4/5 QUOTE"; got != want {
+		t.Errorf("got conflicts %s, want %s", got, want)
+	}
+
+	if got, want := calcTokenSet(d.GetOverlap()), "{ID, QUOTE}"; got != want {
+		t.Errorf("got overlap %s, want %s", got, want)
+	}
+
+	if d.IsLL1() || !a.GetDecisionLookahead(0).IsLL1() {
+		t.Error("IsLL1 differs from the conflicts")
+	}
+}
@@ -46,6 +46,8 @@ type BaseParser struct {
 	tracer         *TraceListener
 	parseListeners []ParseTreeListener
 	_SyntaxErrors  int
+
+	ruleHook parserRuleHook // Set by IncrementalParser while it parses
 }
 
 // p.is all the parsing support code essentially most of it is error
@@ -99,7 +101,7 @@ func (p *BaseParser) reset() {
 	if p.input != nil {
 		p.input.Seek(0)
 	}
-	p.errHandler.reset(p)
+	p.errHandler.Reset(p)
 	p.ctx = nil
 	p._SyntaxErrors = 0
 	p.SetTrace(nil)
@@ -321,8 +323,8 @@ func (p *BaseParser) GetTokenFactory() TokenFactory {
 }
 
 // Tell our token source and error strategy about a Newway to create tokens.//
-func (p *BaseParser) setTokenFactory(factory TokenFactory) {
-	p.input.GetTokenSource().setTokenFactory(factory)
+func (p *BaseParser) SetTokenFactory(factory TokenFactory) {
+	p.input.GetTokenSource().SetTokenFactory(factory)
 }
 
 // The ATN with bypass alternatives is expensive to create so we create it
@@ -425,7 +427,7 @@ func (p *BaseParser) Consume() Token {
 	}
 	hasListener := p.parseListeners != nil && len(p.parseListeners) > 0
 	if p.BuildParseTrees || hasListener {
-		if p.errHandler.inErrorRecoveryMode(p) {
+		if p.errHandler.InErrorRecoveryMode(p) {
 			node := p.ctx.AddErrorNode(o)
 			if p.parseListeners != nil {
 				for _, l := range p.parseListeners {
@@ -464,10 +466,16 @@ func (p *BaseParser) EnterRule(localctx ParserRuleContext, state, ruleIndex int)
 	if p.parseListeners != nil {
 		p.TriggerEnterRuleEvent()
 	}
+	if p.ruleHook != nil {
+		p.ruleHook.enterRule(p, localctx, ruleIndex)
+	}
 }
 
 func (p *BaseParser) ExitRule() {
 	p.ctx.SetStop(p.input.LT(-1))
+	if p.ruleHook != nil {
+		p.ruleHook.exitRule(p, p.ctx)
+	}
 	// trigger event on ctx, before it reverts to parent
 	if p.parseListeners != nil {
 		p.TriggerExitRuleEvent()
@@ -481,6 +489,9 @@ func (p *BaseParser) ExitRule() {
 }
 
 func (p *BaseParser) EnterOuterAlt(localctx ParserRuleContext, altNum int) {
+	if p.ruleHook != nil {
+		p.ruleHook.skipRule(p)
+	}
 	localctx.SetAltNumber(altNum)
 	// if we have Newlocalctx, make sure we replace existing ctx
 	// that is previous child of parse tree
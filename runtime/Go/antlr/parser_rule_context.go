@@ -28,6 +28,9 @@ type ParserRuleContext interface {
 
 	AddChild(child RuleContext) RuleContext
 	RemoveLastChild()
+
+	RemoveChild(child Tree) bool
+	Detach()
 }
 
 type BaseParserRuleContext struct {
@@ -130,6 +133,142 @@ func (prc *BaseParserRuleContext) RemoveLastChild() {
 	}
 }
 
+// TreesInsertChild inserts child into parent before the child at index i, or
+// at the end if i is the number of children, after removing child from its
+// current parent, if any. If child is already a child of parent, i counts the
+// children before child is removed.
+//
+// child gets the parent the parser would have given it: parent itself for a
+// rule node, as in the generated rule functions, and the BaseParserRuleContext
+// embedded in parent for a terminal node, as in AddTokenNode. parent should
+// therefore be the generated context, not the BaseParserRuleContext it embeds.
+// It panics if child is parent or one of its ancestors.
+func TreesInsertChild(parent ParserRuleContext, i int, child Tree) {
+	if child == nil {
+		panic("Child may not be null")
+	}
+
+	checkNotAncestor(parent, child)
+
+	prc := parserRuleContextBase(parent)
+	n := len(prc.children)
+
+	if j := prc.indexOfChild(child); j >= 0 {
+		// Removing child moves the children after it down
+		n--
+
+		if j < i {
+			i--
+		}
+	}
+
+	if i < 0 || i > n {
+		panic("Child index " + strconv.Itoa(i) + " out of range")
+	}
+
+	treeDetach(child)
+
+	prc.children = append(prc.children, nil)
+	copy(prc.children[i+1:], prc.children[i:])
+	prc.children[i] = child
+	child.SetParent(childParent(parent, prc, child))
+}
+
+// TreesReplaceChild puts newChild in the place of oldChild among the children
+// of parent. newChild is removed from its current parent, if any, and gets the
+// parent TreesInsertChild would give it; oldChild is left without a parent. It
+// returns false if oldChild is not a child of parent. It panics if newChild is
+// parent or one of its ancestors.
+func TreesReplaceChild(parent ParserRuleContext, oldChild, newChild Tree) bool {
+	if newChild == nil {
+		panic("Child may not be null")
+	}
+
+	checkNotAncestor(parent, newChild)
+
+	prc := parserRuleContextBase(parent)
+	i := prc.indexOfChild(oldChild)
+
+	if i < 0 {
+		return false
+	}
+
+	if !isSameTreeNode(oldChild, newChild) {
+		treeDetach(newChild)
+
+		// Detaching newChild may have moved oldChild
+		i = prc.indexOfChild(oldChild)
+		prc.children[i] = newChild
+		oldChild.SetParent(nil)
+	}
+
+	newChild.SetParent(childParent(parent, prc, newChild))
+
+	return true
+}
+
+// checkNotAncestor panics if child is parent or one of its ancestors, which
+// would make the tree a cycle if child became a child of parent.
+func checkNotAncestor(parent ParserRuleContext, child Tree) {
+	for t := Tree(parent); t != nil; t = t.GetParent() {
+		if isSameTreeNode(t, child) {
+			panic("Child may not be the parent or one of its ancestors")
+		}
+	}
+}
+
+// parserRuleContextBase returns the BaseParserRuleContext that prc is or
+// embeds.
+func parserRuleContextBase(prc ParserRuleContext) *BaseParserRuleContext {
+	base := findBaseParserRuleContext(reflect.ValueOf(prc))
+
+	if base == nil {
+		panic("Cannot edit the children of " + reflect.TypeOf(prc).String())
+	}
+
+	return base
+}
+
+// childParent returns the parent the parser gives child when it is added to
+// the context parent, whose BaseParserRuleContext is prc.
+func childParent(parent ParserRuleContext, prc *BaseParserRuleContext, child Tree) Tree {
+	if _, ok := child.(RuleNode); ok {
+		return parent
+	}
+
+	return prc
+}
+
+// RemoveChild removes child from prc and clears its parent. It returns false
+// if child is not a child of prc.
+func (prc *BaseParserRuleContext) RemoveChild(child Tree) bool {
+	i := prc.indexOfChild(child)
+
+	if i < 0 {
+		return false
+	}
+
+	prc.children = append(prc.children[:i], prc.children[i+1:]...)
+	child.SetParent(nil)
+
+	return true
+}
+
+// Detach removes prc from the children of its parent, if it has one.
+func (prc *BaseParserRuleContext) Detach() {
+	treeDetach(prc)
+}
+
+func (prc *BaseParserRuleContext) indexOfChild(child Tree) int {
+	for i, c := range prc.children {
+		if isSameTreeNode(c, child) {
+			return i
+		}
+	}
+
+	return -1
+}
+
 func (prc *BaseParserRuleContext) AddTokenNode(token Token) *TerminalNodeImpl {
 
 	node := NewTerminalNodeImpl(token)
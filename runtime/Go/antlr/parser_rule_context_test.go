@@ -0,0 +1,195 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"strings"
+	"testing"
+)
+
+func newInsertChildTestContext(texts ...string) *BaseParserRuleContext {
+	prc := NewBaseParserRuleContext(nil, -1)
+
+	for _, text := range texts {
+		t := NewCommonToken(&TokenSourceCharStreamPair{}, 1, TokenDefaultChannel, 0, 0)
+		t.SetText(text)
+		prc.AddTokenNode(t)
+	}
+
+	return prc
+}
+
+func childTexts(prc *BaseParserRuleContext) string {
+	texts := make([]string, 0)
+
+	for _, c := range prc.GetChildren() {
+		texts = append(texts, c.(TerminalNode).GetText())
+	}
+
+	return strings.Join(texts, " ")
+}
+
+func TestInsertChildMovesChildWithinParent(t *testing.T) {
+	tests := []struct {
+		children string
+		from, to int
+		want     string
+	}{
+		{"a b", 0, 2, "b a"},
+		{"a b", 1, 0, "b a"},
+		{"a b", 0, 0, "a b"},
+		{"a b", 0, 1, "a b"},
+		{"a b c d", 0, 3, "b c a d"},
+		{"a b c d", 3, 1, "a d b c"},
+		{"a b c d", 1, 4, "a c d b"},
+	}
+
+	for _, test := range tests {
+		prc := newInsertChildTestContext(strings.Fields(test.children)...)
+		child := prc.GetChild(test.from)
+
+		TreesInsertChild(prc, test.to, child)
+
+		if got := childTexts(prc); got != test.want {
+			t.Errorf("moving child %d of %q to %d: got %q, want %q", test.from, test.children, test.to, got, test.want)
+		}
+
+		if child.GetParent() != prc {
+			t.Errorf("moving child %d of %q to %d: parent not kept", test.from, test.children, test.to)
+		}
+	}
+}
+
+func TestInsertChildOutOfRangePanicsWithoutDetaching(t *testing.T) {
+	prc := newInsertChildTestContext("a", "b")
+	child := prc.GetChild(0)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("no panic for index 3")
+		}
+
+		if got := childTexts(prc); got != "a b" {
+			t.Errorf("children changed to %q", got)
+		}
+	}()
+
+	TreesInsertChild(prc, 3, child)
+}
+
+func TestTreesEditGeneratedContexts(t *testing.T) {
+	p, tree := parseCalc(calcEditText)
+	prog := tree.(*ProgContext)
+	stats := prog.AllStat()
+	assign := stats[0].(*StatContext)
+	print := stats[1].(*StatContext)
+
+	// Replace a rule label with a copy of another expression
+	value := TreesClone(print.GetArgs()[1]).(*ExprContext)
+	oldValue := assign.GetValue()
+
+	if !TreesReplaceChild(assign, oldValue, value) {
+		t.Fatal("value is not a child of the assignment")
+	}
+
+	assign.SetValue(value)
+
+	if oldValue.GetParent() != nil {
+		t.Errorf("replaced value kept parent %T", oldValue.GetParent())
+	}
+
+	// Replace the error node with a terminal node
+	errorNode := findCalcTokenNodes(prog, CalcParserRPAREN)[0]
+	semi := NewTerminalNodeImpl(print.SEMI().GetSymbol())
+
+	if !TreesReplaceChild(assign, errorNode, semi) {
+		t.Fatal("')' is not a child of the assignment")
+	}
+
+	// Insert a copy of the last statement first and detach the print
+	TreesInsertChild(prog, 0, TreesClone(stats[2]))
+	print.Detach()
+
+	if print.GetParent() != nil {
+		t.Errorf("detached statement kept parent %T", print.GetParent())
+	}
+
+	want := `(prog (stat (str " s ") : (stat (block { (stat (expr (atom y)) ;) }))) (stat x = (expr (atom b)) ; ;) (stat (str " s ") : (stat (block { (stat (expr (atom y)) ;) }))) <EOF>)`
+
+	if got := prog.ToStringTree(nil, p); got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+
+	checkCalcParents(t, "edited", prog)
+	checkStatLabels(t, "edited", prog)
+
+	events := walkCalcEvents(prog)
+
+	if !strings.Contains(events, "terminal ;\nterminal ;\nvalue b\nexit stat") {
+		t.Errorf("walk of edited tree:\n%s", events)
+	}
+
+	if strings.Contains(events, "print") || strings.Contains(events, "error") {
+		t.Errorf("walk of edited tree has removed nodes:\n%s", events)
+	}
+}
+
+func TestTreesInsertChildKeepsGeneratedParent(t *testing.T) {
+	_, tree := parseCalc("x = 1; y = 2;")
+	prog := tree.(*ProgContext)
+	stat := prog.AllStat()[1]
+
+	TreesInsertChild(prog, 0, stat)
+
+	if _, ok := stat.GetParent().(*ProgContext); !ok {
+		t.Errorf("got parent %T, want *ProgContext", stat.GetParent())
+	}
+
+	if got := prog.AllStat()[0]; got != stat {
+		t.Errorf("statement not moved to the front")
+	}
+
+	checkCalcParents(t, "moved", prog)
+}
+
+func TestTreesEditRejectsCycles(t *testing.T) {
+	_, tree := parseCalc("{ x = 1; }")
+	prog := tree.(*ProgContext)
+	stat := prog.AllStat()[0].(*StatContext)
+	block := stat.Block().(*BlockContext)
+	inner := block.AllStat()[0].(*StatContext)
+	want := prog.ToStringTree(calcRuleNames, nil)
+
+	for name, edit := range map[string]func(){
+		"insert self":         func() { TreesInsertChild(inner, 0, inner) },
+		"insert parent":       func() { TreesInsertChild(inner, 0, block) },
+		"insert root":         func() { TreesInsertChild(inner, 0, prog) },
+		"replace with self":   func() { TreesReplaceChild(block, inner, block) },
+		"replace with parent": func() { TreesReplaceChild(inner, inner.GetChild(0), stat) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: no panic", name)
+				}
+			}()
+
+			edit()
+		}()
+
+		if got := prog.ToStringTree(calcRuleNames, nil); got != want {
+			t.Fatalf("%s: tree changed to %s", name, got)
+		}
+	}
+
+	checkCalcParents(t, "rejected", prog)
+
+	// A descendant may still be moved up
+	TreesInsertChild(prog, 0, inner)
+
+	if inner.GetParent() != prog || len(block.AllStat()) != 0 {
+		t.Errorf("statement not moved out of the block")
+	}
+}
@@ -7,6 +7,7 @@ package antlr
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"strconv"
 )
@@ -36,6 +37,11 @@ type BaseRecognizer struct {
 	LiteralNames    []string
 	SymbolicNames   []string
 	GrammarFileName string
+
+	vocabularyOnce   sync.Once
+	vocabulary       Vocabulary
+	ruleIndexMapOnce sync.Once
+	ruleIndexMap     map[string]int
 }
 
 func NewBaseRecognizer() *BaseRecognizer {
@@ -45,9 +51,6 @@ func NewBaseRecognizer() *BaseRecognizer {
 	return rec
 }
 
-var tokenTypeMapCache = make(map[string]int)
-var ruleIndexMapCache = make(map[string]int)
-
 func (b *BaseRecognizer) checkVersion(toolVersion string) {
 	runtimeVersion := "4.7"
 	if runtimeVersion != toolVersion {
@@ -91,78 +94,46 @@ func (b *BaseRecognizer) SetState(v int) {
 	b.state = v
 }
 
-//func (b *Recognizer) GetTokenTypeMap() {
-//    var tokenNames = b.GetTokenNames()
-//    if (tokenNames==nil) {
-//        panic("The current recognizer does not provide a list of token names.")
-//    }
-//    var result = tokenTypeMapCache[tokenNames]
-//    if(result==nil) {
-//        result = tokenNames.reduce(function(o, k, i) { o[k] = i })
-//        result.EOF = TokenEOF
-//        tokenTypeMapCache[tokenNames] = result
-//    }
-//    return result
-//}
+// GetVocabulary returns the Vocabulary of the literal and symbolic names of the
+// recognizer. It is built on first use, so the names must be set by then, as
+// the generated constructors do.
+func (b *BaseRecognizer) GetVocabulary() Vocabulary {
+	b.vocabularyOnce.Do(func() {
+		b.vocabulary = NewVocabularyImpl(b.LiteralNames, b.SymbolicNames, nil)
+	})
+
+	return b.vocabulary
+}
+
+// Get a map from token names to token types.
+//
+// <p>Used for XPath and tree pattern compilation.</p>
+func (b *BaseRecognizer) GetTokenTypeMap() map[string]int {
+	return b.GetVocabulary().GetTokenTypeMap()
+}
 
 // Get a map from rule names to rule indexes.
 //
 // <p>Used for XPath and tree pattern compilation.</p>
 //
 func (b *BaseRecognizer) GetRuleIndexMap() map[string]int {
+	b.ruleIndexMapOnce.Do(func() {
+		b.ruleIndexMap = make(map[string]int, len(b.RuleNames))
 
-	panic("Method not defined!")
-	//    var ruleNames = b.GetRuleNames()
-	//    if (ruleNames==nil) {
-	//        panic("The current recognizer does not provide a list of rule names.")
-	//    }
-	//
-	//    var result = ruleIndexMapCache[ruleNames]
-	//    if(result==nil) {
-	//        result = ruleNames.reduce(function(o, k, i) { o[k] = i })
-	//        ruleIndexMapCache[ruleNames] = result
-	//    }
-	//    return result
+		for i, name := range b.RuleNames {
+			b.ruleIndexMap[name] = i
+		}
+	})
+
+	return b.ruleIndexMap
 }
 
+// Get the token type of a literal or symbolic token name, or TokenInvalidType
+// if there is no such token.
 func (b *BaseRecognizer) GetTokenType(tokenName string) int {
-	panic("Method not defined!")
-	//    var ttype = b.GetTokenTypeMap()[tokenName]
-	//    if (ttype !=nil) {
-	//        return ttype
-	//    } else {
-	//        return TokenInvalidType
-	//    }
+	return b.GetVocabulary().GetTokenType(tokenName)
 }
 
-//func (b *Recognizer) GetTokenTypeMap() map[string]int {
-//    Vocabulary vocabulary = getVocabulary()
-//
-//    Synchronized (tokenTypeMapCache) {
-//        Map<String, Integer> result = tokenTypeMapCache.Get(vocabulary)
-//        if (result == null) {
-//            result = new HashMap<String, Integer>()
-//            for (int i = 0; i < GetATN().maxTokenType; i++) {
-//                String literalName = vocabulary.getLiteralName(i)
-//                if (literalName != null) {
-//                    result.put(literalName, i)
-//                }
-//
-//                String symbolicName = vocabulary.GetSymbolicName(i)
-//                if (symbolicName != null) {
-//                    result.put(symbolicName, i)
-//                }
-//            }
-//
-//            result.put("EOF", Token.EOF)
-//            result = Collections.unmodifiableMap(result)
-//            tokenTypeMapCache.put(vocabulary, result)
-//        }
-//
-//        return result
-//    }
-//}
-
 // What is the error header, normally line/character position information?//
 func (b *BaseRecognizer) GetErrorHeader(e RecognitionException) string {
 	line := e.GetOffendingToken().GetLine()
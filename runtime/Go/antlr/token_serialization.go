@@ -0,0 +1,404 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// TokenSerializationVersion is the version of the binary and JSON forms
+// written by SerializeTokens and SerializeTokensJSON.
+const TokenSerializationVersion = 1
+
+const tokenSerializationMagic = "ATKN"
+
+const (
+	tokenSerializationWithText = 1 << iota
+)
+
+// The binary form written by SerializeTokens is the magic "ATKN" followed by
+// varints: the version, the flags, the length and bytes of the source name,
+// the number of tokens and then, for each token, its type, channel, the
+// distance of its start from the stop of the token before, its length minus
+// one, the number of lines from the token before and its column, and if the
+// text is included, the length and bytes of its text. Signed values are
+// zig-zag encoded, so that typical token lists take a few bytes per token.
+
+// SerializeTokens encodes tokens in the binary form, with their text if
+// withText is true. Otherwise the text is read from the input the tokens are
+// deserialized against, which must be the text they were lexed from.
+func SerializeTokens(tokens []Token, withText bool) []byte {
+	data := []byte(tokenSerializationMagic)
+	flags := 0
+
+	if withText {
+		flags |= tokenSerializationWithText
+	}
+
+	data = appendUvarint(data, TokenSerializationVersion)
+	data = appendUvarint(data, uint64(flags))
+	data = appendTokenString(data, getTokensSourceName(tokens))
+	data = appendUvarint(data, uint64(len(tokens)))
+
+	end, line := 0, 1
+
+	for _, t := range tokens {
+		data = appendVarint(data, int64(t.GetTokenType()))
+		data = appendVarint(data, int64(t.GetChannel()))
+		data = appendVarint(data, int64(t.GetStart()-end))
+		data = appendVarint(data, int64(t.GetStop()-t.GetStart()))
+		data = appendVarint(data, int64(t.GetLine()-line))
+		data = appendVarint(data, int64(t.GetColumn()))
+
+		if withText {
+			data = appendTokenString(data, t.GetText())
+		}
+
+		end, line = t.GetStop()+1, t.GetLine()
+	}
+
+	return data
+}
+
+// DeserializeTokens decodes tokens written by SerializeTokens into a
+// TokenSource that replays them, for example into a CommonTokenStream with
+// SetTokenSource. The text of tokens serialized without it is read from input
+// when asked for, so input may be nil only if the text was included. If the
+// tokens do not end with EOF, one is replayed just after the last token.
+func DeserializeTokens(data []byte, input CharStream) (TokenSource, error) {
+	if len(data) < len(tokenSerializationMagic) || string(data[:len(tokenSerializationMagic)]) != tokenSerializationMagic {
+		return nil, errors.New("antlr: not serialized tokens")
+	}
+
+	r := &tokenReader{data: data, pos: len(tokenSerializationMagic)}
+
+	if version := r.uvarint(); r.err == nil && version != TokenSerializationVersion {
+		return nil, errors.New("antlr: cannot deserialize tokens of version " + strconv.FormatUint(version, 10))
+	}
+
+	flags := r.uvarint()
+	s := newTokenReplaySource(r.string(), input)
+	source := &TokenSourceCharStreamPair{s, input}
+	n := r.uvarint()
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if flags&tokenSerializationWithText == 0 && input == nil {
+		return nil, errNoTokenText
+	}
+
+	end, line := 0, 1
+
+	for i := uint64(0); i < n && r.err == nil; i++ {
+		ttype := int(r.varint())
+		channel := int(r.varint())
+		start := end + int(r.varint())
+		stop := start + int(r.varint())
+
+		line += int(r.varint())
+
+		column := int(r.varint())
+		t := NewCommonToken(source, ttype, channel, start, stop)
+
+		t.tokenIndex = len(s.tokens)
+		t.line = line
+		t.column = column
+
+		if flags&tokenSerializationWithText != 0 {
+			t.SetText(r.string())
+		}
+
+		s.tokens = append(s.tokens, t)
+
+		end = stop + 1
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	s.addEOF()
+
+	return s, nil
+}
+
+type jsonTokenList struct {
+	Version    int                `json:"version"`
+	SourceName string             `json:"sourceName,omitempty"`
+	Tokens     []*jsonCachedToken `json:"tokens"`
+}
+
+type jsonCachedToken struct {
+	Type    int     `json:"type"`
+	Channel int     `json:"channel"`
+	Start   int     `json:"start"`
+	Stop    int     `json:"stop"`
+	Line    int     `json:"line"`
+	Column  int     `json:"column"`
+	Text    *string `json:"text,omitempty"`
+}
+
+// SerializeTokensJSON encodes tokens as JSON, with their text if withText is
+// true. For example:
+//
+//	{"version":1,"sourceName":"a.txt","tokens":[
+//	  {"type":1,"channel":0,"start":0,"stop":1,"line":1,"column":0,"text":"ab"},
+//	  {"type":-1,"channel":0,"start":2,"stop":1,"line":1,"column":2,"text":"<EOF>"}]}
+func SerializeTokensJSON(tokens []Token, withText bool) ([]byte, error) {
+	list := &jsonTokenList{
+		Version:    TokenSerializationVersion,
+		SourceName: getTokensSourceName(tokens),
+		Tokens:     make([]*jsonCachedToken, len(tokens)),
+	}
+
+	for i, t := range tokens {
+		list.Tokens[i] = &jsonCachedToken{
+			Type:    t.GetTokenType(),
+			Channel: t.GetChannel(),
+			Start:   t.GetStart(),
+			Stop:    t.GetStop(),
+			Line:    t.GetLine(),
+			Column:  t.GetColumn(),
+		}
+
+		if withText {
+			text := t.GetText()
+			list.Tokens[i].Text = &text
+		}
+	}
+
+	return json.Marshal(list)
+}
+
+// DeserializeTokensJSON decodes tokens written by SerializeTokensJSON, like
+// DeserializeTokens does.
+func DeserializeTokensJSON(data []byte, input CharStream) (TokenSource, error) {
+	var list jsonTokenList
+
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	if list.Version != TokenSerializationVersion {
+		return nil, errors.New("antlr: cannot deserialize tokens of version " + strconv.Itoa(list.Version))
+	}
+
+	s := newTokenReplaySource(list.SourceName, input)
+	source := &TokenSourceCharStreamPair{s, input}
+
+	for _, jt := range list.Tokens {
+		if jt == nil {
+			return nil, errors.New("antlr: null token in serialized tokens")
+		}
+
+		t := NewCommonToken(source, jt.Type, jt.Channel, jt.Start, jt.Stop)
+
+		t.tokenIndex = len(s.tokens)
+		t.line = jt.Line
+		t.column = jt.Column
+
+		if jt.Text != nil {
+			t.SetText(*jt.Text)
+		} else if input == nil {
+			return nil, errNoTokenText
+		}
+
+		s.tokens = append(s.tokens, t)
+	}
+
+	s.addEOF()
+
+	return s, nil
+}
+
+var errNoTokenText = errors.New("antlr: serialized tokens without text need an input")
+
+// tokenReplaySource replays deserialized tokens, repeating the EOF token that
+// ends them.
+type tokenReplaySource struct {
+	tokens     []Token
+	index      int
+	sourceName string
+	input      CharStream
+	factory    TokenFactory
+}
+
+func newTokenReplaySource(sourceName string, input CharStream) *tokenReplaySource {
+	return &tokenReplaySource{
+		tokens:     make([]Token, 0),
+		sourceName: sourceName,
+		input:      input,
+		factory:    CommonTokenFactoryDEFAULT,
+	}
+}
+
+// addEOF adds an EOF token after the last token unless it is one.
+func (s *tokenReplaySource) addEOF() {
+	if n := len(s.tokens); n > 0 && s.tokens[n-1].GetTokenType() == TokenEOF {
+		return
+	}
+
+	source := &TokenSourceCharStreamPair{s, s.input}
+
+	s.tokens = append(s.tokens, newEOFTokenAfter(s.factory, source, s.tokens))
+}
+
+func (s *tokenReplaySource) NextToken() Token {
+	t := s.tokens[s.index]
+
+	if s.index < len(s.tokens)-1 {
+		s.index++
+	}
+
+	return t
+}
+
+func (s *tokenReplaySource) Skip() {
+}
+
+func (s *tokenReplaySource) More() {
+}
+
+// GetLine returns the line of the next token. While the tokens are being
+// deserialized, there is none yet and it returns 0.
+func (s *tokenReplaySource) GetLine() int {
+	if s.index < len(s.tokens) {
+		return s.tokens[s.index].GetLine()
+	}
+
+	return 0
+}
+
+// GetCharPositionInLine returns the column of the next token, like GetLine.
+func (s *tokenReplaySource) GetCharPositionInLine() int {
+	if s.index < len(s.tokens) {
+		return s.tokens[s.index].GetColumn()
+	}
+
+	return 0
+}
+
+func (s *tokenReplaySource) GetInputStream() CharStream {
+	return s.input
+}
+
+// GetSourceName returns the serialized source name or, if there was none, the
+// name of the input.
+func (s *tokenReplaySource) GetSourceName() string {
+	if s.sourceName == "" && s.input != nil {
+		return s.input.GetSourceName()
+	}
+
+	return s.sourceName
+}
+
+func (s *tokenReplaySource) SetTokenFactory(factory TokenFactory) {
+	s.factory = factory
+}
+
+func (s *tokenReplaySource) GetTokenFactory() TokenFactory {
+	return s.factory
+}
+
+// getTokensSourceName returns the name of the input of the first token that
+// has one.
+func getTokensSourceName(tokens []Token) string {
+	for _, t := range tokens {
+		if input := t.GetInputStream(); input != nil {
+			return input.GetSourceName()
+		}
+	}
+
+	return ""
+}
+
+func appendUvarint(data []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+
+	return append(data, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func appendVarint(data []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+
+	return append(data, buf[:binary.PutVarint(buf[:], v)]...)
+}
+
+func appendTokenString(data []byte, s string) []byte {
+	data = appendUvarint(data, uint64(len(s)))
+
+	return append(data, s...)
+}
+
+// tokenReader reads the varints of serialized tokens, remembering the first
+// error.
+type tokenReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+var errTruncatedTokens = errors.New("antlr: truncated serialized tokens")
+
+func (r *tokenReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+
+	v, n := binary.Uvarint(r.data[r.pos:])
+
+	if n <= 0 {
+		r.err = errTruncatedTokens
+
+		return 0
+	}
+
+	r.pos += n
+
+	return v
+}
+
+func (r *tokenReader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+
+	v, n := binary.Varint(r.data[r.pos:])
+
+	if n <= 0 {
+		r.err = errTruncatedTokens
+
+		return 0
+	}
+
+	r.pos += n
+
+	return v
+}
+
+func (r *tokenReader) string() string {
+	n := r.uvarint()
+
+	if r.err != nil {
+		return ""
+	}
+
+	if n > uint64(len(r.data)-r.pos) {
+		r.err = errTruncatedTokens
+
+		return ""
+	}
+
+	s := string(r.data[r.pos : r.pos+int(n)])
+
+	r.pos += int(n)
+
+	return s
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tokenSerializationFormats are the binary and JSON forms under test.
+var tokenSerializationFormats = []struct {
+	name        string
+	serialize   func(tokens []Token, withText bool) []byte
+	deserialize func(data []byte, input CharStream) (TokenSource, error)
+}{
+	{"binary", SerializeTokens, DeserializeTokens},
+	{"JSON", func(tokens []Token, withText bool) []byte {
+		data, err := SerializeTokensJSON(tokens, withText)
+
+		if err != nil {
+			panic(err)
+		}
+
+		return data
+	}, DeserializeTokensJSON},
+}
+
+func lexCalcTokens(text string) []Token {
+	stream := NewCommonTokenStream(NewCalcLexer(NewInputStream(text)), TokenDefaultChannel)
+
+	stream.Fill()
+
+	return stream.GetAllTokens()
+}
+
+func replayTokens(source TokenSource) []Token {
+	stream := NewCommonTokenStream(NewCalcLexer(nil), TokenDefaultChannel)
+
+	stream.SetTokenSource(source)
+	stream.Fill()
+
+	return stream.GetAllTokens()
+}
+
+func TestTokenSerializationRoundTrip(t *testing.T) {
+	tokens := lexCalcTokens(calcIncrementalText)
+	want := tokenDescriptions(tokens)
+
+	for _, format := range tokenSerializationFormats {
+		for _, withText := range []bool{true, false} {
+			data := format.serialize(tokens, withText)
+			input := NewInputStream(calcIncrementalText)
+
+			if withText {
+				input = nil
+			}
+
+			source, err := format.deserialize(data, input)
+
+			if err != nil {
+				t.Fatalf("%s with text %v: %s", format.name, withText, err)
+			}
+
+			if got := tokenDescriptions(replayTokens(source)); got != want {
+				t.Errorf("%s with text %v: got\n%s\nwant\n%s", format.name, withText, got, want)
+			}
+
+			// EOF is repeated once the tokens are replayed
+			if eof := source.NextToken(); eof.GetTokenType() != TokenEOF || eof.GetTokenIndex() != len(tokens)-1 {
+				t.Errorf("%s with text %v: got %v after the tokens, want EOF", format.name, withText, eof)
+			}
+		}
+	}
+}
+
+func TestTokenSerializationSynthesizesEOF(t *testing.T) {
+	tokens := lexCalcTokens(calcIncrementalText)
+	want := tokenDescriptions(tokens)
+
+	// The EOF token is left out, so it has to be put back where it was
+	tokens = tokens[:len(tokens)-1]
+
+	for _, format := range tokenSerializationFormats {
+		for _, withText := range []bool{true, false} {
+			source, err := format.deserialize(format.serialize(tokens, withText), NewInputStream(calcIncrementalText))
+
+			if err != nil {
+				t.Fatalf("%s with text %v: %s", format.name, withText, err)
+			}
+
+			if got := tokenDescriptions(replayTokens(source)); got != want {
+				t.Errorf("%s with text %v: got\n%s\nwant\n%s", format.name, withText, got, want)
+			}
+		}
+	}
+
+	for _, format := range tokenSerializationFormats {
+		source, err := format.deserialize(format.serialize(nil, true), nil)
+
+		if err != nil {
+			t.Fatalf("%s without tokens: %s", format.name, err)
+		}
+
+		if got, want := tokenDescriptions(replayTokens(source)), "0 type -1 0--1 line 1:0 channel 0 \"<EOF>\"\n"; got != want {
+			t.Errorf("%s without tokens: got %q, want %q", format.name, got, want)
+		}
+	}
+}
+
+func TestTokenSerializationSourceName(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "a.calc")
+
+	if err := ioutil.WriteFile(fileName, []byte("x;"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stream := NewCommonTokenStream(NewCalcLexer(NewFileStream(fileName)), TokenDefaultChannel)
+
+	stream.Fill()
+
+	for _, format := range tokenSerializationFormats {
+		source, err := format.deserialize(format.serialize(stream.GetAllTokens(), true), nil)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if name := source.GetSourceName(); name != fileName {
+			t.Errorf("%s: got source name %q, want %q", format.name, name, fileName)
+		}
+	}
+
+	// Without a serialized name, the name of the input is used
+	source, err := DeserializeTokens(SerializeTokens(nil, false), NewFileStream(fileName))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name := source.GetSourceName(); name != fileName {
+		t.Errorf("got source name %q, want %q", name, fileName)
+	}
+}
+
+func TestTokenSerializationRequiresInputWithoutText(t *testing.T) {
+	tokens := lexCalcTokens("x = 1;")
+
+	for _, format := range tokenSerializationFormats {
+		if _, err := format.deserialize(format.serialize(tokens, false), nil); err != errNoTokenText {
+			t.Errorf("%s: got error %v, want %v", format.name, err, errNoTokenText)
+		}
+	}
+}
+
+func TestDeserializeTokensTruncated(t *testing.T) {
+	for _, withText := range []bool{true, false} {
+		data := SerializeTokens(lexCalcTokens(calcIncrementalText), withText)
+
+		for n := 0; n < len(data); n++ {
+			if _, err := DeserializeTokens(data[:n], NewInputStream(calcIncrementalText)); err == nil {
+				t.Fatalf("with text %v: no error for %d of %d bytes", withText, n, len(data))
+			}
+		}
+	}
+}
+
+func TestDeserializeTokensJSONTruncated(t *testing.T) {
+	data, err := SerializeTokensJSON(lexCalcTokens(calcIncrementalText), true)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for n := 0; n < len(data); n++ {
+		if _, err := DeserializeTokensJSON(data[:n], nil); err == nil {
+			t.Fatalf("no error for %d of %d bytes", n, len(data))
+		}
+	}
+
+	if _, err := DeserializeTokensJSON([]byte(`{"version":1,"tokens":[null]}`), nil); err == nil {
+		t.Error("no error for a null token")
+	}
+}
+
+func TestDeserializeTokensWrongVersion(t *testing.T) {
+	data := SerializeTokens(lexCalcTokens("x;"), true)
+
+	// The version is the varint just after the magic
+	data[len(tokenSerializationMagic)] = TokenSerializationVersion + 1
+
+	if _, err := DeserializeTokens(data, nil); err == nil || !strings.Contains(err.Error(), "version 2") {
+		t.Errorf("binary: got error %v, want a version error", err)
+	}
+
+	if _, err := DeserializeTokens([]byte("NOPE"), nil); err == nil {
+		t.Error("binary: no error without the magic")
+	}
+
+	data, err := SerializeTokensJSON(lexCalcTokens("x;"), true)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data = []byte(strings.Replace(string(data), `"version":1`, `"version":2`, 1))
+
+	if _, err := DeserializeTokensJSON(data, nil); err == nil || !strings.Contains(err.Error(), "version 2") {
+		t.Errorf("JSON: got error %v, want a version error", err)
+	}
+}
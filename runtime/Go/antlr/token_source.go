@@ -4,6 +4,7 @@
 
 package antlr
 
+// TokenSource is the source of the tokens of a token stream, usually a Lexer.
 type TokenSource interface {
 	NextToken() Token
 	Skip()
@@ -12,6 +13,6 @@ type TokenSource interface {
 	GetCharPositionInLine() int
 	GetInputStream() CharStream
 	GetSourceName() string
-	setTokenFactory(factory TokenFactory)
+	SetTokenFactory(factory TokenFactory)
 	GetTokenFactory() TokenFactory
 }
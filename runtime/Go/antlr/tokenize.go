@@ -0,0 +1,193 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"errors"
+	"strconv"
+)
+
+// TokenizeOptions selects the tokens returned by Tokenize, TokenizeFunc and
+// LexerTokenIterator. The zero value selects the tokens of all channels,
+// without EOF.
+type TokenizeOptions struct {
+	// IncludeEOF adds the EOF token at the end.
+	IncludeEOF bool
+
+	// Channels lists the channels to return the tokens of, or is nil for all
+	// channels.
+	Channels []int
+}
+
+// LexerError is a token recognition error, as a lexer reports it to its error
+// listeners.
+type LexerError struct {
+	Line      int
+	Column    int
+	Msg       string
+	Exception RecognitionException
+}
+
+func (e *LexerError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + ":" + strconv.Itoa(e.Column) + " " + e.Msg
+}
+
+// TokenIterator returns tokens one at a time.
+type TokenIterator interface {
+	// Next returns the next token, or nil when there are no more.
+	Next() Token
+}
+
+// LexerTokenIterator lexes a token each time Next is called. The lexer's error
+// listeners are not called while it lexes; the errors are collected instead.
+type LexerTokenIterator struct {
+	lexer     Lexer
+	options   TokenizeOptions
+	collector *lexerErrorCollector
+	done      bool
+}
+
+var _ TokenIterator = &LexerTokenIterator{}
+
+// NewLexerTokenIterator returns an iterator over the tokens lexer produces
+// from the rest of its input. The options may be nil.
+func NewLexerTokenIterator(lexer Lexer, options *TokenizeOptions) *LexerTokenIterator {
+	it := &LexerTokenIterator{
+		lexer:     lexer,
+		collector: &lexerErrorCollector{DefaultErrorListener: NewDefaultErrorListener()},
+	}
+
+	if options != nil {
+		it.options = *options
+	}
+
+	return it
+}
+
+func (it *LexerTokenIterator) Next() Token {
+	if it.done {
+		return nil
+	}
+
+	defer swapErrorListeners(it.lexer, it.collector)()
+
+	for {
+		t := it.lexer.NextToken()
+
+		if t.GetTokenType() == TokenEOF {
+			it.done = true
+
+			if it.options.IncludeEOF {
+				return t
+			}
+
+			return nil
+		}
+
+		if it.accepts(t) {
+			return t
+		}
+	}
+}
+
+// GetErrors returns the errors reported so far, as *LexerError values.
+func (it *LexerTokenIterator) GetErrors() []error {
+	return it.collector.errors
+}
+
+func (it *LexerTokenIterator) accepts(t Token) bool {
+	if it.options.Channels == nil {
+		return true
+	}
+
+	for _, c := range it.options.Channels {
+		if t.GetChannel() == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Tokenize lexes the rest of the input of lexer and returns the tokens
+// selected by options, which may be nil, and the errors, as *LexerError
+// values, instead of reporting them to the error listeners.
+func Tokenize(lexer Lexer, options *TokenizeOptions) ([]Token, []error) {
+	tokens := make([]Token, 0)
+
+	errs := TokenizeFunc(lexer, options, func(t Token) bool {
+		tokens = append(tokens, t)
+
+		return true
+	})
+
+	return tokens, errs
+}
+
+// TokenizeString is like Tokenize but first sets the input of lexer to text.
+// The lexer must have a SetInputStream method, as lexers embedding BaseLexer
+// do; if it has none, TokenizeString returns no tokens and only that error.
+func TokenizeString(lexer Lexer, text string, options *TokenizeOptions) ([]Token, []error) {
+	l, ok := lexer.(interface {
+		SetInputStream(CharStream)
+	})
+
+	if !ok {
+		return nil, []error{errNoSetInputStream}
+	}
+
+	l.SetInputStream(NewInputStream(text))
+
+	return Tokenize(lexer, options)
+}
+
+var errNoSetInputStream = errors.New("antlr: lexer cannot set its input stream")
+
+// TokenizeFunc is like Tokenize but passes the tokens to f as they are lexed,
+// stopping early if f returns false.
+func TokenizeFunc(lexer Lexer, options *TokenizeOptions, f func(Token) bool) []error {
+	it := NewLexerTokenIterator(lexer, options)
+
+	for t := it.Next(); t != nil; t = it.Next() {
+		if !f(t) {
+			break
+		}
+	}
+
+	return it.GetErrors()
+}
+
+// swapErrorListeners makes listener the only error listener of r and returns
+// a function that gives r back the listeners it had.
+func swapErrorListeners(r Recognizer, listener ErrorListener) func() {
+	saved := r.GetErrorListenerDispatch()
+
+	r.RemoveErrorListeners()
+	r.AddErrorListener(listener)
+
+	return func() {
+		r.RemoveErrorListeners()
+
+		if proxy, ok := saved.(*ProxyErrorListener); ok {
+			// Add the listeners back one by one instead of nesting proxies
+			for _, d := range proxy.delegates {
+				r.AddErrorListener(d)
+			}
+		} else {
+			r.AddErrorListener(saved)
+		}
+	}
+}
+
+// lexerErrorCollector is the error listener of LexerTokenIterator.
+type lexerErrorCollector struct {
+	*DefaultErrorListener
+
+	errors []error
+}
+
+func (c *lexerErrorCollector) SyntaxError(recognizer Recognizer, offendingSymbol interface{}, line, column int, msg string, e RecognitionException) {
+	c.errors = append(c.errors, &LexerError{Line: line, Column: column, Msg: msg, Exception: e})
+}
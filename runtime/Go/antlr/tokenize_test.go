@@ -0,0 +1,145 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"strings"
+	"testing"
+)
+
+// tokenTexts returns the texts of tokens, with EOF as "EOF".
+func tokenTexts(tokens []Token) string {
+	texts := make([]string, 0, len(tokens))
+
+	for _, t := range tokens {
+		if t.GetTokenType() == TokenEOF {
+			texts = append(texts, "EOF")
+		} else {
+			texts = append(texts, t.GetText())
+		}
+	}
+
+	return strings.Join(texts, "|")
+}
+
+func TestTokenizeOptions(t *testing.T) {
+	tests := []struct {
+		options *TokenizeOptions
+		want    string
+	}{
+		{nil, "x| |=| |1|;"},
+		{&TokenizeOptions{IncludeEOF: true}, "x| |=| |1|;|EOF"},
+		{&TokenizeOptions{Channels: []int{TokenDefaultChannel}}, "x|=|1|;"},
+		{&TokenizeOptions{Channels: []int{TokenHiddenChannel}, IncludeEOF: true}, " | |EOF"},
+		{&TokenizeOptions{Channels: []int{}}, ""},
+	}
+
+	for _, test := range tests {
+		tokens, errs := TokenizeString(NewCalcLexer(nil), "x = 1;", test.options)
+
+		if got := tokenTexts(tokens); got != test.want || len(errs) != 0 {
+			t.Errorf("%+v: got %q and errors %v, want %q", test.options, got, errs, test.want)
+		}
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	tokens, errs := TokenizeString(NewCalcLexer(nil), "x @\n$ ;", &TokenizeOptions{Channels: []int{TokenDefaultChannel}})
+
+	if got, want := tokenTexts(tokens), "x|;"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	want := []string{
+		"line 1:2 token recognition error at: '@'",
+		"line 2:0 token recognition error at: '$'",
+	}
+
+	if len(errs) != len(want) {
+		t.Fatalf("got errors %v, want %q", errs, want)
+	}
+
+	for i, err := range errs {
+		e, ok := err.(*LexerError)
+
+		if !ok {
+			t.Fatalf("got error %v of type %T, want a *LexerError", err, err)
+		}
+
+		if e.Error() != want[i] || e.Exception == nil {
+			t.Errorf("got %q with exception %v, want %q", e.Error(), e.Exception, want[i])
+		}
+	}
+}
+
+func TestTokenizeFuncStopsEarly(t *testing.T) {
+	lexer := NewCalcLexer(NewInputStream("x = 1; @"))
+	texts := make([]string, 0)
+
+	errs := TokenizeFunc(lexer, &TokenizeOptions{Channels: []int{TokenDefaultChannel}}, func(t Token) bool {
+		texts = append(texts, t.GetText())
+
+		return len(texts) < 2
+	})
+
+	if got := strings.Join(texts, "|"); got != "x|=" || len(errs) != 0 {
+		t.Errorf("got %q and errors %v", got, errs)
+	}
+
+	// The lexer goes on after the last token passed to f
+	if got := lexer.NextToken().GetText(); got != " " {
+		t.Errorf("got next token %q", got)
+	}
+}
+
+func TestTokenizeRestoresErrorListeners(t *testing.T) {
+	lexer := NewCalcLexer(NewInputStream("@ x $"))
+	first := &calcErrorRecorder{DefaultErrorListener: NewDefaultErrorListener()}
+	second := &calcErrorRecorder{DefaultErrorListener: NewDefaultErrorListener()}
+
+	lexer.RemoveErrorListeners()
+	lexer.AddErrorListener(first)
+	lexer.AddErrorListener(second)
+
+	it := NewLexerTokenIterator(lexer, &TokenizeOptions{Channels: []int{TokenDefaultChannel}})
+
+	if got := it.Next().GetText(); got != "x" {
+		t.Fatalf("got first token %q", got)
+	}
+
+	// The listeners are back between calls to Next
+	delegates := lexer.GetErrorListenerDispatch().(*ProxyErrorListener).delegates
+
+	if len(delegates) != 2 || delegates[0] != first || delegates[1] != second {
+		t.Fatalf("got listeners %v", delegates)
+	}
+
+	if it.Next() != nil || it.Next() != nil {
+		t.Error("tokens after the last one")
+	}
+
+	if len(it.GetErrors()) != 2 || len(first.errors) != 0 || len(second.errors) != 0 {
+		t.Errorf("got errors %v, listeners called with %q and %q", it.GetErrors(), first.errors, second.errors)
+	}
+
+	// Errors outside Tokenize reach the listeners again
+	lexer.SetInputStream(NewInputStream("$"))
+	lexer.NextToken()
+
+	if len(first.errors) != 1 || len(second.errors) != 1 {
+		t.Errorf("got errors %q and %q", first.errors, second.errors)
+	}
+}
+
+func TestTokenizeStringWithoutSetInputStream(t *testing.T) {
+	// A lexer without a SetInputStream method
+	lexer := struct{ Lexer }{NewCalcLexer(nil)}
+
+	tokens, errs := TokenizeString(lexer, "x", nil)
+
+	if tokens != nil || len(errs) != 1 || errs[0] != errNoSetInputStream {
+		t.Errorf("got tokens %v and errors %v", tokens, errs)
+	}
+}
@@ -144,7 +144,16 @@ func (t *TerminalNodeImpl) GetParent() Tree {
 }
 
 func (t *TerminalNodeImpl) SetParent(tree Tree) {
-	t.parentCtx = tree.(RuleContext)
+	if tree == nil {
+		t.parentCtx = nil
+	} else {
+		t.parentCtx = tree.(RuleContext)
+	}
+}
+
+// Detach removes t from the children of its parent, if it has one.
+func (t *TerminalNodeImpl) Detach() {
+	treeDetach(t)
 }
 
 func (t *TerminalNodeImpl) GetPayload() interface{} {
@@ -207,6 +216,54 @@ func (e *ErrorNodeImpl) Accept(v ParseTreeVisitor) interface{} {
 	return v.VisitErrorNode(e)
 }
 
+// treeDetach removes t from the children of its parent, if it has one, and
+// clears the parent.
+func treeDetach(t Tree) {
+	parent := t.GetParent()
+
+	if parent == nil {
+		return
+	}
+
+	if prc, ok := parent.(ParserRuleContext); ok && prc.RemoveChild(t) {
+		return
+	}
+
+	t.SetParent(nil)
+}
+
+// isSameTreeNode reports whether a and b are the same node. A generated
+// context and the BaseParserRuleContext it embeds are the same node, as are an
+// ErrorNodeImpl and its TerminalNodeImpl.
+func isSameTreeNode(a, b Tree) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	if ra, ok := a.(RuleNode); ok {
+		rb, ok := b.(RuleNode)
+
+		return ok && ra.GetBaseRuleContext() == rb.GetBaseRuleContext()
+	}
+
+	if ta := terminalNodeImpl(a); ta != nil {
+		return ta == terminalNodeImpl(b)
+	}
+
+	return a == b
+}
+
+func terminalNodeImpl(t Tree) *TerminalNodeImpl {
+	switch tt := t.(type) {
+	case *TerminalNodeImpl:
+		return tt
+	case *ErrorNodeImpl:
+		return tt.TerminalNodeImpl
+	}
+
+	return nil
+}
+
 type ParseTreeWalker struct {
 }
 
@@ -249,3 +306,147 @@ func (p *ParseTreeWalker) ExitRule(listener ParseTreeListener, r RuleNode) {
 }
 
 var ParseTreeWalkerDefault = NewParseTreeWalker()
+
+// IterativeParseTreeWalker sends the same events in the same order as
+// ParseTreeWalker, but keeps track of its position in the tree with an explicit
+// stack instead of recursing, so very deep trees do not grow the goroutine
+// stack.
+//
+// A listener that also implements WalkController can prune the walk: after
+// each event the walker takes the action the listener requested, if any.
+type IterativeParseTreeWalker struct {
+	*ParseTreeWalker
+}
+
+func NewIterativeParseTreeWalker() *IterativeParseTreeWalker {
+	return &IterativeParseTreeWalker{ParseTreeWalker: NewParseTreeWalker()}
+}
+
+type iterativeWalkFrame struct {
+	node  Tree
+	index int
+}
+
+func (i *IterativeParseTreeWalker) Walk(listener ParseTreeListener, t Tree) {
+	control, _ := listener.(WalkController)
+
+	// Forget any action requested outside of a walk
+	takeWalkAction(control)
+
+	stack := make([]iterativeWalkFrame, 0)
+	current := t
+
+	for current != nil {
+		switch tt := current.(type) {
+		case ErrorNode:
+			listener.VisitErrorNode(tt)
+
+			if takeWalkAction(control) == WalkActionStop {
+				return
+			}
+		case TerminalNode:
+			listener.VisitTerminal(tt)
+
+			if takeWalkAction(control) == WalkActionStop {
+				return
+			}
+		default:
+			i.EnterRule(listener, current.(RuleNode))
+
+			action := takeWalkAction(control)
+
+			if action == WalkActionStop {
+				return
+			}
+
+			if action != WalkActionSkipChildren && current.GetChildCount() > 0 {
+				stack = append(stack, iterativeWalkFrame{current, 0})
+				current = current.GetChild(0)
+
+				continue
+			}
+
+			i.ExitRule(listener, current.(RuleNode))
+
+			if takeWalkAction(control) == WalkActionStop {
+				return
+			}
+		}
+
+		// Move on to the next sibling, leaving every rule whose children
+		// have all been walked
+		current = nil
+
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			top.index++
+
+			if top.index < top.node.GetChildCount() {
+				current = top.node.GetChild(top.index)
+
+				break
+			}
+
+			stack = stack[:len(stack)-1]
+			i.ExitRule(listener, top.node.(RuleNode))
+
+			if takeWalkAction(control) == WalkActionStop {
+				return
+			}
+		}
+	}
+}
+
+// The actions a WalkController can request from IterativeParseTreeWalker.
+// WalkActionSkipChildren only has an effect when requested while entering a
+// rule; the rule is still exited. After WalkActionStop no more events are sent.
+const (
+	WalkActionContinue     = 0
+	WalkActionSkipChildren = 1
+	WalkActionStop         = 2
+)
+
+// WalkController is an optional interface for listeners, checked by
+// IterativeParseTreeWalker. Listeners usually get it by embedding
+// BaseWalkController and calling SkipChildren or StopWalk from their enter,
+// exit and visit methods.
+type WalkController interface {
+	// TakeWalkAction returns the action requested since the last call and
+	// resets it to WalkActionContinue.
+	TakeWalkAction() int
+}
+
+type BaseWalkController struct {
+	action int
+}
+
+var _ WalkController = &BaseWalkController{}
+
+// SkipChildren asks the walker not to walk the children of the rule being
+// entered.
+func (c *BaseWalkController) SkipChildren() {
+	if c.action != WalkActionStop {
+		c.action = WalkActionSkipChildren
+	}
+}
+
+// StopWalk asks the walker to stop without sending any more events.
+func (c *BaseWalkController) StopWalk() {
+	c.action = WalkActionStop
+}
+
+func (c *BaseWalkController) TakeWalkAction() int {
+	action := c.action
+
+	c.action = WalkActionContinue
+
+	return action
+}
+
+func takeWalkAction(control WalkController) int {
+	if control == nil {
+		return WalkActionContinue
+	}
+
+	return control.TakeWalkAction()
+}
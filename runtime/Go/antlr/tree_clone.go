@@ -0,0 +1,242 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"reflect"
+	"strings"
+)
+
+var (
+	parserRuleContextType        = reflect.TypeOf((*ParserRuleContext)(nil)).Elem()
+	baseParserRuleContextPtrType = reflect.TypeOf((*BaseParserRuleContext)(nil))
+)
+
+// TreesClone returns a deep copy of the parse tree t. Every context is copied
+// with its concrete type, so generated contexts keep their accessors, and
+// their labels are redirected to the copied children through the generated
+// Get and Set methods. Tokens are shared with the original tree. The copy of t
+// has no parent.
+func TreesClone(t ParseTree) ParseTree {
+	clones := make(map[Tree]Tree)
+	clone := cloneTreeNode(t, clones)
+
+	clone.SetParent(nil)
+
+	for orig, c := range clones {
+		if _, ok := orig.(RuleNode); ok {
+			remapContextFields(reflect.ValueOf(c), clones)
+		}
+	}
+
+	return clone.(ParseTree)
+}
+
+// cloneTreeNode copies t and its descendants, recording each copy in clones.
+// The parent of the copy of t is left to the caller.
+func cloneTreeNode(t Tree, clones map[Tree]Tree) Tree {
+	var clone Tree
+
+	switch tt := t.(type) {
+	case *ErrorNodeImpl:
+		clone = NewErrorNodeImpl(tt.symbol)
+
+	case *TerminalNodeImpl:
+		clone = NewTerminalNodeImpl(tt.symbol)
+
+	case RuleNode:
+		v, base := cloneContextValue(reflect.ValueOf(t))
+
+		if base == nil {
+			panic("Cannot clone tree node of type " + reflect.TypeOf(t).String())
+		}
+
+		clone = v.Interface().(Tree)
+
+		for _, child := range tt.GetChildren() {
+			c := cloneTreeNode(child, clones)
+
+			base.children = append(base.children, c)
+
+			// Keep the parent the original child had: either the generated
+			// context or the BaseParserRuleContext it embeds
+			if child.GetParent() == nil {
+				c.SetParent(nil)
+			} else if prc, ok := child.GetParent().(*BaseParserRuleContext); ok && prc.BaseRuleContext == tt.GetBaseRuleContext() {
+				c.SetParent(base)
+			} else {
+				c.SetParent(clone)
+			}
+		}
+
+	default:
+		panic("Cannot clone tree node of type " + reflect.TypeOf(t).String())
+	}
+
+	clones[t] = clone
+
+	return clone
+}
+
+// cloneContextValue returns a copy of the context struct v points to, in which
+// the embedded contexts are copied as well, together with the
+// BaseParserRuleContext of the copy. The copy has no children.
+func cloneContextValue(v reflect.Value) (reflect.Value, *BaseParserRuleContext) {
+	if v.Type() == baseParserRuleContextPtrType {
+		prc := v.Interface().(*BaseParserRuleContext)
+		base := *prc
+		rc := *prc.BaseRuleContext
+
+		base.BaseRuleContext = &rc
+		base.children = nil
+
+		return reflect.ValueOf(&base), &base
+	}
+
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return v, nil
+	}
+
+	clone := reflect.New(v.Elem().Type())
+	s := clone.Elem()
+
+	s.Set(v.Elem())
+
+	var base *BaseParserRuleContext
+
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+
+		if !s.Type().Field(i).Anonymous || f.Kind() != reflect.Ptr || f.IsNil() || !f.CanSet() || !f.Type().Implements(parserRuleContextType) {
+			continue
+		}
+
+		c, b := cloneContextValue(f)
+
+		f.Set(c)
+
+		if b != nil {
+			base = b
+		}
+	}
+
+	return clone, base
+}
+
+// remapContextFields points the labels of the context v that refer to nodes of
+// the original tree, such as rule and alternative labels, at their copies.
+// Generated contexts keep labels in unexported fields, so they are read and
+// written with the Get and Set methods generated for them.
+//
+// Only rule labels are remapped: Get and Set pairs that v does not inherit
+// from BaseParserRuleContext and whose type is a rule context or a slice of
+// them. Token labels need no remapping as tokens are shared, and the accessors
+// of rule arguments, locals and return values are not called. A rule context
+// attribute that is not a label is remapped too if it holds a node of the
+// original tree.
+func remapContextFields(v reflect.Value, clones map[Tree]Tree) {
+	if v.Type() == baseParserRuleContextPtrType || v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+
+	s := v.Elem()
+
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+
+		// Labels of an embedded context may be hidden by methods of v
+		if s.Type().Field(i).Anonymous && f.Kind() == reflect.Ptr && !f.IsNil() && f.CanInterface() && f.Type().Implements(parserRuleContextType) {
+			remapContextFields(f, clones)
+		}
+	}
+
+	for i := 0; i < v.NumMethod(); i++ {
+		name := v.Type().Method(i).Name
+
+		if !strings.HasPrefix(name, "Set") {
+			continue
+		}
+
+		if _, ok := baseParserRuleContextPtrType.MethodByName(name); ok {
+			continue
+		}
+
+		set := v.Method(i)
+		get := v.MethodByName("Get" + strings.TrimPrefix(name, "Set"))
+
+		if !get.IsValid() || get.Type().NumIn() != 0 || get.Type().NumOut() != 1 || set.Type().NumIn() != 1 || set.Type().NumOut() != 0 || set.Type().In(0) != get.Type().Out(0) {
+			continue
+		}
+
+		labelType := get.Type().Out(0)
+
+		if labelType.Kind() == reflect.Slice {
+			labelType = labelType.Elem()
+		}
+
+		if (labelType.Kind() != reflect.Interface && labelType.Kind() != reflect.Ptr) || !labelType.Implements(parserRuleContextType) {
+			continue
+		}
+
+		f := get.Call(nil)[0]
+
+		if f.Kind() != reflect.Slice {
+			if c := lookupClone(f, clones); c.IsValid() {
+				set.Call([]reflect.Value{c})
+			}
+
+			continue
+		}
+
+		if f.IsNil() {
+			continue
+		}
+
+		list := reflect.MakeSlice(f.Type(), f.Len(), f.Len())
+		changed := false
+
+		reflect.Copy(list, f)
+
+		for j := 0; j < list.Len(); j++ {
+			if c := lookupClone(list.Index(j), clones); c.IsValid() {
+				list.Index(j).Set(c)
+				changed = true
+			}
+		}
+
+		if changed {
+			set.Call([]reflect.Value{list})
+		}
+	}
+}
+
+// lookupClone returns the copy of the tree node held by v, or the zero Value
+// if v does not hold a node of the original tree or the copy cannot be stored
+// in v.
+func lookupClone(v reflect.Value, clones map[Tree]Tree) reflect.Value {
+	if v.IsNil() {
+		return reflect.Value{}
+	}
+
+	t, ok := v.Interface().(Tree)
+
+	if !ok {
+		return reflect.Value{}
+	}
+
+	c, ok := clones[t]
+
+	if !ok {
+		return reflect.Value{}
+	}
+
+	cv := reflect.ValueOf(c)
+
+	if !cv.Type().AssignableTo(v.Type()) {
+		return reflect.Value{}
+	}
+
+	return cv
+}
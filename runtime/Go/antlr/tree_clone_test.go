@@ -0,0 +1,190 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// calcEditText has rule labels, list labels, a rule argument and an error
+// node for the extraneous ')'.
+const calcEditText = "x = a + 1 ) ;\nprint a, b;\n\"s\" : { y; }"
+
+// calcEventRecorder records the events of a walk over a Calc parse tree,
+// including the labels of each stat and the argument of each expr. onEvent,
+// if set, is called with each event as it is recorded.
+type calcEventRecorder struct {
+	*BaseCalcListener
+	events  []string
+	onEvent func(event string)
+}
+
+func (r *calcEventRecorder) record(event string) {
+	r.events = append(r.events, event)
+
+	if r.onEvent != nil {
+		r.onEvent(event)
+	}
+}
+
+func (r *calcEventRecorder) VisitTerminal(node TerminalNode) {
+	r.record("terminal " + node.GetText())
+}
+
+func (r *calcEventRecorder) VisitErrorNode(node ErrorNode) {
+	r.record("error " + node.GetText())
+}
+
+func (r *calcEventRecorder) EnterEveryRule(ctx ParserRuleContext) {
+	r.record("enter " + calcRuleNames[ctx.GetRuleIndex()])
+}
+
+func (r *calcEventRecorder) ExitEveryRule(ctx ParserRuleContext) {
+	r.record("exit " + calcRuleNames[ctx.GetRuleIndex()])
+}
+
+func (r *calcEventRecorder) ExitStat(ctx *StatContext) {
+	if ctx.GetValue() != nil {
+		r.record("value " + ctx.GetValue().GetText())
+	}
+
+	for _, arg := range ctx.GetArgs() {
+		r.record("arg " + arg.GetText())
+	}
+}
+
+func (r *calcEventRecorder) ExitExpr(ctx *ExprContext) {
+	r.record(fmt.Sprintf("p %d", ctx.GetP()))
+}
+
+// findCalcTokenNodes returns the terminal nodes of tree with token type
+// tokenType, in tree order.
+func findCalcTokenNodes(tree ParseTree, tokenType int) []TerminalNode {
+	nodes := make([]TerminalNode, 0)
+
+	for _, node := range TreesDescendants(tree) {
+		if tn, ok := node.(TerminalNode); ok && tn.GetSymbol().GetTokenType() == tokenType {
+			nodes = append(nodes, tn)
+		}
+	}
+
+	return nodes
+}
+
+func walkCalcEvents(t Tree) string {
+	r := &calcEventRecorder{BaseCalcListener: &BaseCalcListener{}}
+
+	ParseTreeWalkerDefault.Walk(r, t)
+
+	return strings.Join(r.events, "\n")
+}
+
+// checkCalcParents checks that every node below ctx has the parent the parser
+// gives it: the generated context for rule nodes and the embedded
+// BaseParserRuleContext for terminal nodes.
+func checkCalcParents(t *testing.T, name string, ctx ParserRuleContext) {
+	base := parserRuleContextBase(ctx)
+
+	for i, child := range ctx.GetChildren() {
+		if c, ok := child.(ParserRuleContext); ok {
+			if c.GetParent() != Tree(ctx) {
+				t.Errorf("%s: child %d of %T has parent %T", name, i, ctx, c.GetParent())
+			}
+
+			checkCalcParents(t, name, c)
+		} else if child.GetParent() != Tree(base) {
+			t.Errorf("%s: terminal %d of %T has parent %T", name, i, ctx, child.GetParent())
+		}
+	}
+}
+
+// checkStatLabels checks that the labels of every stat below tree refer to
+// children of that stat.
+func checkStatLabels(t *testing.T, name string, tree ParseTree) {
+	for _, node := range TreesDescendants(tree) {
+		stat, ok := node.(*StatContext)
+
+		if !ok {
+			continue
+		}
+
+		labels := make([]Tree, 0)
+
+		if stat.GetValue() != nil {
+			labels = append(labels, stat.GetValue())
+		}
+
+		for _, arg := range stat.GetArgs() {
+			labels = append(labels, arg)
+		}
+
+		for _, label := range labels {
+			if stat.indexOfChild(label) < 0 {
+				t.Errorf("%s: label %q of stat %q is not a child of it", name, label.(ParseTree).GetText(), stat.GetText())
+			}
+		}
+
+		if stat.GetId() != nil && stat.GetId() != stat.ID().GetSymbol() {
+			t.Errorf("%s: id label of stat %q is not its ID token", name, stat.GetText())
+		}
+	}
+}
+
+func TestTreesCloneGeneratedContexts(t *testing.T) {
+	p, tree := parseCalc(calcEditText)
+	events := walkCalcEvents(tree)
+	text := tree.ToStringTree(nil, p)
+
+	clone, ok := TreesClone(tree).(*ProgContext)
+
+	if !ok {
+		t.Fatalf("clone has type %T", clone)
+	}
+
+	if clone.GetParent() != nil {
+		t.Errorf("clone has parent %T", clone.GetParent())
+	}
+
+	if got := clone.ToStringTree(nil, p); got != text {
+		t.Errorf("got %s, want %s", got, text)
+	}
+
+	if got := walkCalcEvents(clone); got != events {
+		t.Errorf("walk of clone:\n%s\nwant:\n%s", got, events)
+	}
+
+	checkCalcParents(t, "clone", clone)
+	checkStatLabels(t, "clone", clone)
+
+	rparens := findCalcTokenNodes(clone, CalcParserRPAREN)
+
+	if len(rparens) != 1 {
+		t.Fatalf("got %d ')' nodes, want 1", len(rparens))
+	}
+
+	if _, ok := rparens[0].(*ErrorNodeImpl); !ok {
+		t.Errorf("')' is not an error node in the clone")
+	}
+
+	original := tree.(*ProgContext).AllStat()[0].(*StatContext)
+	copied := clone.AllStat()[0].(*StatContext)
+
+	if isSameTreeNode(original.GetValue(), copied.GetValue()) {
+		t.Errorf("value label of the clone refers to the original tree")
+	}
+
+	if copied.GetId() != original.GetId() {
+		t.Errorf("clone does not share the id token")
+	}
+
+	// Editing the clone leaves the original alone
+	copied.Detach()
+
+	if got := tree.ToStringTree(nil, p); got != text {
+		t.Errorf("original changed to %s", got)
+	}
+}
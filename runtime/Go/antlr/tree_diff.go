@@ -0,0 +1,433 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/fnv"
+	"strconv"
+)
+
+// Kinds of TreeEdit.
+const (
+	TreeEditInsert = iota
+	TreeEditDelete
+	TreeEditRelabel
+	TreeEditMove
+)
+
+// TreeEdit is one step of the edit script that turns one parse tree into
+// another. OldPath is the path of the node in the old tree and NewPath its
+// path in the new tree, each a list of child indexes from the root. Inserts
+// have no OldPath and deletes have no NewPath. A relabel replaces a node by one
+// of the same kind, rule or token, and keeps its children; the differences
+// between the children are listed as separate edits.
+type TreeEdit struct {
+	Kind    int
+	OldPath []int
+	NewPath []int
+	OldNode Tree
+	NewNode Tree
+}
+
+// TreeDiff is the result of TreesDiff.
+type TreeDiff struct {
+	Edits []*TreeEdit
+}
+
+// TreesDiff compares the parse trees oldTree and newTree by structure. Rule
+// nodes are equal if they have the same rule index and terminal nodes if their
+// tokens have the same type and text, so the trees may come from different
+// parser instances of the same grammar. Children are matched in order, and a
+// subtree that is deleted in one place and inserted unchanged in another is
+// reported as a move.
+func TreesDiff(oldTree, newTree Tree) *TreeDiff {
+	d := &treeDiffer{
+		signatures: make(map[Tree]uint64),
+		equal:      make(map[[2]Tree]bool),
+		edits:      make([]*TreeEdit, 0),
+	}
+
+	d.addSignatures(oldTree)
+	d.addSignatures(newTree)
+	d.diffNodes(oldTree, newTree, []int{}, []int{})
+	d.findMoves()
+
+	return &TreeDiff{Edits: d.edits}
+}
+
+// IsEmpty returns true if the trees compared are structurally equal.
+func (d *TreeDiff) IsEmpty() bool {
+	return len(d.Edits) == 0
+}
+
+// GetReport returns a human-readable list of the edits, one per line, naming
+// rules with ruleNames, which may be nil. For example:
+//
+//	~ relabel /0/1 -> /0/1: '*' -> '+'
+//	- delete /2: expr
+//	+ insert /1/0: 'x'
+//	> move /3 -> /0/2: atom
+func (d *TreeDiff) GetReport(ruleNames []string) string {
+	var buf bytes.Buffer
+
+	for _, e := range d.Edits {
+		buf.WriteString(e.Report(ruleNames))
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
+
+// Report returns the line of TreeDiff.GetReport for e.
+func (e *TreeEdit) Report(ruleNames []string) string {
+	switch e.Kind {
+	case TreeEditInsert:
+		return "+ insert " + treeDiffPath(e.NewPath) + ": " + treeDiffNodeText(e.NewNode, ruleNames)
+	case TreeEditDelete:
+		return "- delete " + treeDiffPath(e.OldPath) + ": " + treeDiffNodeText(e.OldNode, ruleNames)
+	case TreeEditRelabel:
+		return "~ relabel " + treeDiffPath(e.OldPath) + " -> " + treeDiffPath(e.NewPath) + ": " +
+			treeDiffNodeText(e.OldNode, ruleNames) + " -> " + treeDiffNodeText(e.NewNode, ruleNames)
+	case TreeEditMove:
+		return "> move " + treeDiffPath(e.OldPath) + " -> " + treeDiffPath(e.NewPath) + ": " + treeDiffNodeText(e.OldNode, ruleNames)
+	}
+
+	return "? unknown edit " + strconv.Itoa(e.Kind)
+}
+
+func (e *TreeEdit) String() string {
+	return e.Report(nil)
+}
+
+func treeDiffPath(path []int) string {
+	if len(path) == 0 {
+		return "/"
+	}
+
+	var buf bytes.Buffer
+
+	for _, i := range path {
+		buf.WriteString("/")
+		buf.WriteString(strconv.Itoa(i))
+	}
+
+	return buf.String()
+}
+
+func treeDiffNodeText(t Tree, ruleNames []string) string {
+	switch tt := t.(type) {
+	case RuleNode:
+		ruleIndex := tt.GetRuleContext().GetRuleIndex()
+
+		if ruleIndex >= 0 && ruleIndex < len(ruleNames) {
+			return ruleNames[ruleIndex]
+		}
+
+		return "rule " + strconv.Itoa(ruleIndex)
+
+	case TerminalNode:
+		if tt.GetSymbol() == nil {
+			return "token"
+		}
+
+		return "'" + tt.GetSymbol().GetText() + "'"
+	}
+
+	return "node"
+}
+
+type treeDiffer struct {
+	signatures map[Tree]uint64
+	equal      map[[2]Tree]bool
+	edits      []*TreeEdit
+}
+
+// label returns the key by which nodes are compared, ignoring their children.
+func (d *treeDiffer) label(t Tree) string {
+	switch tt := t.(type) {
+	case RuleNode:
+		return "r" + strconv.Itoa(tt.GetRuleContext().GetRuleIndex())
+
+	case TerminalNode:
+		prefix := "t"
+
+		if _, ok := t.(ErrorNode); ok {
+			prefix = "e"
+		}
+
+		if tt.GetSymbol() == nil {
+			return prefix
+		}
+
+		return prefix + strconv.Itoa(tt.GetSymbol().GetTokenType()) + ":" + strconv.Quote(tt.GetSymbol().GetText())
+	}
+
+	return "?"
+}
+
+type treeDiffFrame struct {
+	node  Tree
+	index int
+}
+
+// addSignatures computes the signature of every subtree of t, children first,
+// with an explicit stack so that deep trees do not grow the goroutine stack.
+// The signature of a node is a hash of its label and of the signatures of its
+// children, so it takes constant space per node.
+func (d *treeDiffer) addSignatures(t Tree) {
+	stack := []treeDiffFrame{{t, 0}}
+
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+
+		if top.index < top.node.GetChildCount() {
+			child := top.node.GetChild(top.index)
+			top.index++
+
+			if _, ok := d.signatures[child]; !ok {
+				stack = append(stack, treeDiffFrame{child, 0})
+			}
+
+			continue
+		}
+
+		h := fnv.New64a()
+		word := make([]byte, 8)
+
+		h.Write([]byte(d.label(top.node)))
+
+		for i := 0; i < top.node.GetChildCount(); i++ {
+			binary.LittleEndian.PutUint64(word, d.signatures[top.node.GetChild(i)])
+			h.Write(word)
+		}
+
+		d.signatures[top.node] = h.Sum64()
+		stack = stack[:len(stack)-1]
+	}
+}
+
+// signature returns the key by which whole subtrees are compared. Subtrees
+// with different signatures differ; sameSubtree tells whether subtrees with
+// the same signature are equal.
+func (d *treeDiffer) signature(t Tree) uint64 {
+	if s, ok := d.signatures[t]; ok {
+		return s
+	}
+
+	d.addSignatures(t)
+
+	return d.signatures[t]
+}
+
+// sameSubtree returns true if a and b have equal labels and equal children,
+// recursively.
+func (d *treeDiffer) sameSubtree(a, b Tree) bool {
+	if d.signature(a) != d.signature(b) {
+		return false
+	}
+
+	key := [2]Tree{a, b}
+
+	if equal, ok := d.equal[key]; ok {
+		return equal
+	}
+
+	equal := true
+	stack := [][2]Tree{key}
+
+	for len(stack) > 0 {
+		pair := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		x, y := pair[0], pair[1]
+
+		if d.label(x) != d.label(y) || x.GetChildCount() != y.GetChildCount() {
+			equal = false
+
+			break
+		}
+
+		for i := 0; i < x.GetChildCount(); i++ {
+			stack = append(stack, [2]Tree{x.GetChild(i), y.GetChild(i)})
+		}
+	}
+
+	d.equal[key] = equal
+
+	return equal
+}
+
+func treeDiffSameKind(a, b Tree) bool {
+	_, ra := a.(RuleNode)
+	_, rb := b.(RuleNode)
+
+	return ra == rb
+}
+
+func (d *treeDiffer) diffNodes(a, b Tree, pathA, pathB []int) {
+	if d.sameSubtree(a, b) {
+		return
+	}
+
+	if !treeDiffSameKind(a, b) {
+		d.edits = append(d.edits, &TreeEdit{Kind: TreeEditDelete, OldPath: pathA, OldNode: a})
+		d.edits = append(d.edits, &TreeEdit{Kind: TreeEditInsert, NewPath: pathB, NewNode: b})
+
+		return
+	}
+
+	if d.label(a) != d.label(b) {
+		d.edits = append(d.edits, &TreeEdit{Kind: TreeEditRelabel, OldPath: pathA, NewPath: pathB, OldNode: a, NewNode: b})
+	}
+
+	d.diffChildren(a, b, pathA, pathB)
+}
+
+// diffChildren matches the children of a and b by the heaviest common
+// subsequence in which children with equal subtrees weigh more than children
+// with only equal labels, so that unchanged subtrees are matched with each
+// other rather than with edited ones. Unmatched children between two matches
+// are paired in order while they are of the same kind and the rest are deleted
+// or inserted.
+func (d *treeDiffer) diffChildren(a, b Tree, pathA, pathB []int) {
+	n, m := a.GetChildCount(), b.GetChildCount()
+
+	weight := func(i, j int) int {
+		x, y := a.GetChild(i), b.GetChild(j)
+
+		if d.sameSubtree(x, y) {
+			return 3
+		}
+
+		if d.label(x) == d.label(y) {
+			return 1
+		}
+
+		return 0
+	}
+
+	// best[i][j] is the weight of the heaviest common subsequence of the
+	// children of a from i and of b from j
+	best := make([][]int, n+1)
+
+	for i := range best {
+		best[i] = make([]int, m+1)
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			best[i][j] = intMax(best[i+1][j], best[i][j+1])
+
+			if w := weight(i, j); w > 0 {
+				best[i][j] = intMax(best[i][j], best[i+1][j+1]+w)
+			}
+		}
+	}
+
+	i, j := 0, 0
+	gapA, gapB := 0, 0
+
+	for i < n && j < m {
+		w := weight(i, j)
+
+		switch {
+		case w > 0 && best[i][j] == best[i+1][j+1]+w:
+			d.diffGap(a, b, pathA, pathB, gapA, i, gapB, j)
+			d.diffNodes(a.GetChild(i), b.GetChild(j), treeDiffChildPath(pathA, i), treeDiffChildPath(pathB, j))
+
+			i++
+			j++
+			gapA, gapB = i, j
+
+		case best[i+1][j] >= best[i][j+1]:
+			i++
+
+		default:
+			j++
+		}
+	}
+
+	d.diffGap(a, b, pathA, pathB, gapA, n, gapB, m)
+}
+
+// diffGap compares the unmatched children a[fromA:toA] and b[fromB:toB].
+func (d *treeDiffer) diffGap(a, b Tree, pathA, pathB []int, fromA, toA, fromB, toB int) {
+	i, j := fromA, fromB
+
+	for i < toA && j < toB && treeDiffSameKind(a.GetChild(i), b.GetChild(j)) {
+		d.diffNodes(a.GetChild(i), b.GetChild(j), treeDiffChildPath(pathA, i), treeDiffChildPath(pathB, j))
+
+		i++
+		j++
+	}
+
+	for ; i < toA; i++ {
+		d.edits = append(d.edits, &TreeEdit{Kind: TreeEditDelete, OldPath: treeDiffChildPath(pathA, i), OldNode: a.GetChild(i)})
+	}
+
+	for ; j < toB; j++ {
+		d.edits = append(d.edits, &TreeEdit{Kind: TreeEditInsert, NewPath: treeDiffChildPath(pathB, j), NewNode: b.GetChild(j)})
+	}
+}
+
+// findMoves replaces each delete and insert of equal subtrees by a move.
+func (d *treeDiffer) findMoves() {
+	inserts := make(map[uint64][]int)
+
+	for k, e := range d.edits {
+		if e.Kind == TreeEditInsert {
+			sig := d.signature(e.NewNode)
+			inserts[sig] = append(inserts[sig], k)
+		}
+	}
+
+	removed := make(map[int]bool)
+
+	for _, e := range d.edits {
+		if e.Kind != TreeEditDelete {
+			continue
+		}
+
+		sig := d.signature(e.OldNode)
+		candidates := inserts[sig]
+
+		for c, k := range candidates {
+			if !d.sameSubtree(e.OldNode, d.edits[k].NewNode) {
+				continue
+			}
+
+			insert := d.edits[k]
+			inserts[sig] = append(candidates[:c:c], candidates[c+1:]...)
+			removed[k] = true
+
+			e.Kind = TreeEditMove
+			e.NewPath = insert.NewPath
+			e.NewNode = insert.NewNode
+
+			break
+		}
+	}
+
+	edits := make([]*TreeEdit, 0, len(d.edits)-len(removed))
+
+	for k, e := range d.edits {
+		if !removed[k] {
+			edits = append(edits, e)
+		}
+	}
+
+	d.edits = edits
+}
+
+func treeDiffChildPath(path []int, i int) []int {
+	child := make([]int, len(path)+1)
+
+	copy(child, path)
+	child[len(path)] = i
+
+	return child
+}
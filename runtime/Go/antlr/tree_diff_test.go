@@ -0,0 +1,80 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTreesDiff(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     string
+	}{
+		{"x = 1; y = 2;", "x = 1; y = 2;", ""},
+		{"x = 1; y = 2;", "y = 2; x = 1;", "> move /0 -> /1: stat\n"},
+		{"x = 1; print a;", "x = 2; print a, b;", "~ relabel /0/2/0/0 -> /0/2/0/0: '1' -> '2'\n+ insert /1/2: ','\n+ insert /1/3: expr\n"},
+		{"x = 1;", "{ x = 1; }", "- delete /0/0: 'x'\n- delete /0/1: '='\n- delete /0/2: expr\n- delete /0/3: ';'\n+ insert /0/0: block\n"},
+	}
+
+	for _, test := range tests {
+		_, oldTree := parseCalc(test.old)
+		_, newTree := parseCalc(test.new)
+
+		if got := TreesDiff(oldTree, newTree).GetReport(calcRuleNames); got != test.want {
+			t.Errorf("%q -> %q:\ngot:\n%s\nwant:\n%s", test.old, test.new, got, test.want)
+		}
+	}
+}
+
+func TestTreesDiffDeepTree(t *testing.T) {
+	const depth = 20000
+
+	text := "x = " + strings.Repeat("(", depth) + "1" + strings.Repeat(")", depth) + ";"
+	_, oldTree := parseCalc(text)
+	_, newTree := parseCalc(text + " y;")
+
+	if got, want := TreesDiff(oldTree, newTree).GetReport(calcRuleNames), "+ insert /1: stat\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// A change at the bottom is compared through every level, each edit
+	// holding its full path
+	const changedDepth = 1000
+
+	text = "x = " + strings.Repeat("(", changedDepth) + "1" + strings.Repeat(")", changedDepth) + ";"
+	_, oldTree = parseCalc(text)
+	_, newTree = parseCalc(strings.Replace(text, "1", "2", 1))
+	edits := TreesDiff(oldTree, newTree).Edits
+
+	if len(edits) != 1 || edits[0].Kind != TreeEditRelabel || len(edits[0].OldPath) != 2*changedDepth+4 {
+		t.Errorf("got %d edits, want a relabel at depth %d", len(edits), 2*changedDepth+4)
+	}
+}
+
+func TestTreesDiffSignatureCollision(t *testing.T) {
+	_, tree := parseCalc("x = 1; x = 2;")
+	stats := tree.(*ProgContext).AllStat()
+
+	d := &treeDiffer{
+		signatures: make(map[Tree]uint64),
+		equal:      make(map[[2]Tree]bool),
+	}
+
+	d.addSignatures(tree)
+
+	if d.sameSubtree(stats[0], stats[1]) {
+		t.Fatal("different statements are the same")
+	}
+
+	// Equal signatures alone do not make subtrees equal
+	d.signatures[stats[1]] = d.signatures[stats[0]]
+	d.equal = make(map[[2]Tree]bool)
+
+	if d.sameSubtree(stats[0], stats[1]) {
+		t.Error("colliding statements are the same")
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+// TreeIterator visits tree nodes lazily, one per call to Next, so callers can
+// stop early without the whole result being built up front as it is by
+// TreesDescendants or TreesfindAllNodes.
+type TreeIterator interface {
+	// Next returns the next node, or nil when there are no more.
+	Next() Tree
+}
+
+// TreeFilter reports whether a node should be returned by a filtered
+// TreeIterator.
+type TreeFilter func(Tree) bool
+
+// TreeFilterRuleIndex returns a TreeFilter that accepts the rule nodes of rule
+// ruleIndex.
+func TreeFilterRuleIndex(ruleIndex int) TreeFilter {
+	return func(t Tree) bool {
+		r, ok := t.(RuleNode)
+
+		return ok && r.GetRuleContext().GetRuleIndex() == ruleIndex
+	}
+}
+
+// TreeFilterTokenType returns a TreeFilter that accepts the terminal nodes,
+// including error nodes, whose token is of type ttype.
+func TreeFilterTokenType(ttype int) TreeFilter {
+	return func(t Tree) bool {
+		n, ok := t.(TerminalNode)
+
+		return ok && n.GetSymbol() != nil && n.GetSymbol().GetTokenType() == ttype
+	}
+}
+
+type preOrderTreeIterator struct {
+	stack []Tree
+}
+
+// NewPreOrderTreeIterator returns an iterator over t and its descendants,
+// each node before its children, in the order TreesDescendants lists them.
+func NewPreOrderTreeIterator(t Tree) TreeIterator {
+	it := &preOrderTreeIterator{stack: make([]Tree, 0)}
+
+	if t != nil {
+		it.stack = append(it.stack, t)
+	}
+
+	return it
+}
+
+func (it *preOrderTreeIterator) Next() Tree {
+	if len(it.stack) == 0 {
+		return nil
+	}
+
+	t := it.stack[len(it.stack)-1]
+	it.stack = it.stack[:len(it.stack)-1]
+
+	// Push the children in reverse so the first child is returned next
+	for i := t.GetChildCount() - 1; i >= 0; i-- {
+		it.stack = append(it.stack, t.GetChild(i))
+	}
+
+	return t
+}
+
+type postOrderTreeIterator struct {
+	stack []iterativeWalkFrame
+}
+
+// NewPostOrderTreeIterator returns an iterator over t and its descendants,
+// each node after its children.
+func NewPostOrderTreeIterator(t Tree) TreeIterator {
+	it := &postOrderTreeIterator{stack: make([]iterativeWalkFrame, 0)}
+
+	if t != nil {
+		it.stack = append(it.stack, iterativeWalkFrame{t, 0})
+	}
+
+	return it
+}
+
+func (it *postOrderTreeIterator) Next() Tree {
+	for len(it.stack) > 0 {
+		top := &it.stack[len(it.stack)-1]
+
+		if top.index < top.node.GetChildCount() {
+			child := top.node.GetChild(top.index)
+			top.index++
+			it.stack = append(it.stack, iterativeWalkFrame{child, 0})
+
+			continue
+		}
+
+		it.stack = it.stack[:len(it.stack)-1]
+
+		return top.node
+	}
+
+	return nil
+}
+
+type breadthFirstTreeIterator struct {
+	queue []Tree
+}
+
+// NewBreadthFirstTreeIterator returns an iterator over t and its descendants,
+// level by level.
+func NewBreadthFirstTreeIterator(t Tree) TreeIterator {
+	it := &breadthFirstTreeIterator{queue: make([]Tree, 0)}
+
+	if t != nil {
+		it.queue = append(it.queue, t)
+	}
+
+	return it
+}
+
+func (it *breadthFirstTreeIterator) Next() Tree {
+	if len(it.queue) == 0 {
+		return nil
+	}
+
+	t := it.queue[0]
+	it.queue = it.queue[1:]
+
+	for i := 0; i < t.GetChildCount(); i++ {
+		it.queue = append(it.queue, t.GetChild(i))
+	}
+
+	return t
+}
+
+type ancestorTreeIterator struct {
+	current Tree
+}
+
+// NewAncestorTreeIterator returns an iterator over the ancestors of t, from
+// its parent up to the root. This is the reverse of the order of
+// TreesgetAncestors.
+func NewAncestorTreeIterator(t Tree) TreeIterator {
+	return &ancestorTreeIterator{current: t}
+}
+
+func (it *ancestorTreeIterator) Next() Tree {
+	if it.current == nil {
+		return nil
+	}
+
+	it.current = it.current.GetParent()
+
+	return it.current
+}
+
+type siblingTreeIterator struct {
+	node   Tree
+	parent Tree
+	index  int
+}
+
+// NewSiblingTreeIterator returns an iterator over the other children of the
+// parent of t, in order. It returns nothing if t is nil or has no parent.
+func NewSiblingTreeIterator(t Tree) TreeIterator {
+	it := &siblingTreeIterator{node: t}
+
+	if t != nil {
+		it.parent = t.GetParent()
+	}
+
+	return it
+}
+
+func (it *siblingTreeIterator) Next() Tree {
+	if it.parent == nil {
+		return nil
+	}
+
+	for it.index < it.parent.GetChildCount() {
+		child := it.parent.GetChild(it.index)
+		it.index++
+
+		if !isSameTreeNode(child, it.node) {
+			return child
+		}
+	}
+
+	return nil
+}
+
+type filteredTreeIterator struct {
+	it     TreeIterator
+	filter TreeFilter
+}
+
+// NewFilteredTreeIterator returns an iterator over the nodes returned by it
+// that are accepted by filter.
+func NewFilteredTreeIterator(it TreeIterator, filter TreeFilter) TreeIterator {
+	return &filteredTreeIterator{it: it, filter: filter}
+}
+
+func (f *filteredTreeIterator) Next() Tree {
+	for t := f.it.Next(); t != nil; t = f.it.Next() {
+		if f.filter(t) {
+			return t
+		}
+	}
+
+	return nil
+}
+
+// TreesForEach calls f with each node returned by it until f returns false or
+// there are no more nodes.
+func TreesForEach(it TreeIterator, f func(Tree) bool) {
+	for t := it.Next(); t != nil; t = it.Next() {
+		if !f(t) {
+			return
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"strings"
+	"testing"
+)
+
+// iteratedTexts returns the node texts of the nodes returned by it.
+func iteratedTexts(it TreeIterator) string {
+	texts := make([]string, 0)
+
+	for t := it.Next(); t != nil; t = it.Next() {
+		texts = append(texts, TreesGetNodeText(t, calcRuleNames, nil))
+	}
+
+	return strings.Join(texts, " ")
+}
+
+func TestTreeIteratorOrders(t *testing.T) {
+	_, tree := parseCalc("x = 1;")
+	stat := tree.(*ProgContext).AllStat()[0].(*StatContext)
+	one := findCalcTokenNodes(tree, CalcParserINT)[0]
+
+	tests := []struct {
+		name string
+		it   TreeIterator
+		want string
+	}{
+		{"pre-order", NewPreOrderTreeIterator(tree), "prog stat x = expr atom 1 ; <EOF>"},
+		{"post-order", NewPostOrderTreeIterator(tree), "x = 1 atom expr ; stat <EOF> prog"},
+		{"breadth-first", NewBreadthFirstTreeIterator(tree), "prog stat <EOF> x = expr ; atom 1"},
+		{"ancestors", NewAncestorTreeIterator(one), "atom expr stat prog"},
+		{"siblings", NewSiblingTreeIterator(stat.ASSIGN()), "x expr ;"},
+		{"root siblings", NewSiblingTreeIterator(tree), ""},
+		{"filtered", NewFilteredTreeIterator(NewPreOrderTreeIterator(tree), TreeFilterTokenType(CalcParserID)), "x"},
+	}
+
+	for _, test := range tests {
+		if got := iteratedTexts(test.it); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+
+	// The pre-order matches TreesDescendants
+	want := make([]string, 0)
+
+	for _, d := range TreesDescendants(tree) {
+		want = append(want, TreesGetNodeText(d, calcRuleNames, nil))
+	}
+
+	if got := iteratedTexts(NewPreOrderTreeIterator(tree)); got != strings.Join(want, " ") {
+		t.Errorf("got pre-order %q, want %q", got, strings.Join(want, " "))
+	}
+}
+
+func TestTreeIteratorsOfNil(t *testing.T) {
+	for name, it := range map[string]TreeIterator{
+		"pre-order":     NewPreOrderTreeIterator(nil),
+		"post-order":    NewPostOrderTreeIterator(nil),
+		"breadth-first": NewBreadthFirstTreeIterator(nil),
+		"ancestors":     NewAncestorTreeIterator(nil),
+		"siblings":      NewSiblingTreeIterator(nil),
+	} {
+		if got := it.Next(); got != nil {
+			t.Errorf("%s: got %v", name, got)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// The JSON form of a parse tree written by TreesToJSON. A rule node has a
+// ruleIndex, rule name, alt number and children. A terminal node has a token,
+// and error nodes are also flagged with "error": true. For example:
+//
+//	{"ruleIndex":0,"rule":"expr","altNumber":1,"children":[
+//	  {"token":{"type":1,"text":"x","channel":0,"line":1,"column":0,"start":0,"stop":0,"tokenIndex":0}},
+//	  {"error":true,"token":{"type":2,"text":"<missing ')'>","channel":0,"line":1,"column":1,"start":-1,"stop":-1,"tokenIndex":-1}}]}
+type jsonTreeNode struct {
+	RuleIndex *int            `json:"ruleIndex,omitempty"`
+	Rule      string          `json:"rule,omitempty"`
+	AltNumber *int            `json:"altNumber,omitempty"`
+	Error     bool            `json:"error,omitempty"`
+	Token     *jsonToken      `json:"token,omitempty"`
+	Children  []*jsonTreeNode `json:"children,omitempty"`
+}
+
+type jsonToken struct {
+	Type       int    `json:"type"`
+	Text       string `json:"text"`
+	Channel    int    `json:"channel"`
+	Line       int    `json:"line"`
+	Column     int    `json:"column"`
+	Start      int    `json:"start"`
+	Stop       int    `json:"stop"`
+	TokenIndex int    `json:"tokenIndex"`
+}
+
+// TreesToJSON encodes the parse tree t as JSON. Rule nodes are named using
+// ruleNames, which may be nil.
+func TreesToJSON(t ParseTree, ruleNames []string) ([]byte, error) {
+	node, err := newJSONTreeNode(t, ruleNames)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(node)
+}
+
+func newJSONTreeNode(t Tree, ruleNames []string) (*jsonTreeNode, error) {
+	switch tt := t.(type) {
+	case ErrorNode:
+		return &jsonTreeNode{Error: true, Token: newJSONToken(tt.GetSymbol())}, nil
+
+	case TerminalNode:
+		return &jsonTreeNode{Token: newJSONToken(tt.GetSymbol())}, nil
+
+	case RuleNode:
+		ctx := tt.GetRuleContext()
+		ruleIndex := ctx.GetRuleIndex()
+		altNumber := ctx.GetAltNumber()
+		node := &jsonTreeNode{RuleIndex: &ruleIndex, AltNumber: &altNumber}
+
+		if ruleIndex >= 0 && ruleIndex < len(ruleNames) {
+			node.Rule = ruleNames[ruleIndex]
+		} else if d, ok := ctx.(*JSONParserRuleContext); ok {
+			node.Rule = d.ruleName
+		}
+
+		for i := 0; i < t.GetChildCount(); i++ {
+			child, err := newJSONTreeNode(t.GetChild(i), ruleNames)
+
+			if err != nil {
+				return nil, err
+			}
+
+			node.Children = append(node.Children, child)
+		}
+
+		return node, nil
+	}
+
+	return nil, errors.New("antlr: cannot encode parse tree node of unknown type")
+}
+
+func newJSONToken(t Token) *jsonToken {
+	if t == nil {
+		return &jsonToken{Type: TokenInvalidType, Start: -1, Stop: -1, TokenIndex: -1}
+	}
+
+	return &jsonToken{
+		Type:       t.GetTokenType(),
+		Text:       t.GetText(),
+		Channel:    t.GetChannel(),
+		Line:       t.GetLine(),
+		Column:     t.GetColumn(),
+		Start:      t.GetStart(),
+		Stop:       t.GetStop(),
+		TokenIndex: t.GetTokenIndex(),
+	}
+}
+
+// JSONParserRuleContext is the type of the rule nodes of trees decoded by
+// TreesFromJSON. It keeps the rule name and alt number that were encoded.
+type JSONParserRuleContext struct {
+	*BaseParserRuleContext
+
+	ruleName  string
+	altNumber int
+}
+
+func NewJSONParserRuleContext(parent ParserRuleContext, ruleIndex int, ruleName string, altNumber int) *JSONParserRuleContext {
+	prc := &JSONParserRuleContext{
+		BaseParserRuleContext: NewBaseParserRuleContext(parent, -1),
+		ruleName:              ruleName,
+		altNumber:             altNumber,
+	}
+
+	prc.RuleIndex = ruleIndex
+
+	return prc
+}
+
+func (prc *JSONParserRuleContext) GetRuleName() string {
+	return prc.ruleName
+}
+
+func (prc *JSONParserRuleContext) GetAltNumber() int {
+	return prc.altNumber
+}
+
+func (prc *JSONParserRuleContext) SetAltNumber(altNumber int) {
+	prc.altNumber = altNumber
+}
+
+func (prc *JSONParserRuleContext) GetRuleContext() RuleContext {
+	return prc
+}
+
+func (prc *JSONParserRuleContext) Accept(visitor ParseTreeVisitor) interface{} {
+	return visitor.VisitChildren(prc)
+}
+
+// TreesFromJSON decodes a parse tree written by TreesToJSON. Rule nodes are
+// JSONParserRuleContexts whose start and stop tokens are the first and last
+// token below them, as the parser sets them, so EOF is never a stop token.
+// Terminal and error nodes hold CommonTokens without a source.
+func TreesFromJSON(data []byte) (ParseTree, error) {
+	var node jsonTreeNode
+
+	if err := json.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+
+	return node.toParseTree(nil)
+}
+
+func (n *jsonTreeNode) toParseTree(parent *JSONParserRuleContext) (ParseTree, error) {
+	if (n.Token == nil) == (n.RuleIndex == nil) {
+		return nil, errors.New("antlr: parse tree node must have exactly one of token and ruleIndex")
+	}
+
+	if n.Token != nil {
+		token := n.Token.toToken()
+
+		var node TerminalNode
+
+		if n.Error {
+			en := NewErrorNodeImpl(token)
+
+			if parent != nil {
+				en.parentCtx = parent
+			}
+
+			node = en
+		} else {
+			tn := NewTerminalNodeImpl(token)
+
+			if parent != nil {
+				tn.parentCtx = parent
+			}
+
+			node = tn
+		}
+
+		return node, nil
+	}
+
+	altNumber := ATNInvalidAltNumber
+
+	if n.AltNumber != nil {
+		altNumber = *n.AltNumber
+	}
+
+	var ctx *JSONParserRuleContext
+
+	if parent == nil {
+		ctx = NewJSONParserRuleContext(nil, *n.RuleIndex, n.Rule, altNumber)
+	} else {
+		ctx = NewJSONParserRuleContext(parent, *n.RuleIndex, n.Rule, altNumber)
+	}
+
+	for _, c := range n.Children {
+		child, err := c.toParseTree(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ctx.children = append(ctx.children, child)
+
+		switch cc := child.(type) {
+		case TerminalNode:
+			if ctx.start == nil {
+				ctx.start = cc.GetSymbol()
+			}
+
+			// The parser does not consume EOF, so it is never a stop token
+			if cc.GetSymbol().GetTokenType() != TokenEOF {
+				ctx.stop = cc.GetSymbol()
+			}
+
+		case ParserRuleContext:
+			if ctx.start == nil {
+				ctx.start = cc.GetStart()
+			}
+
+			if cc.GetStop() != nil {
+				ctx.stop = cc.GetStop()
+			}
+		}
+	}
+
+	return ctx, nil
+}
+
+func (t *jsonToken) toToken() Token {
+	token := NewCommonToken(&TokenSourceCharStreamPair{}, t.Type, t.Channel, t.Start, t.Stop)
+
+	token.SetText(t.Text)
+	token.SetTokenIndex(t.TokenIndex)
+	token.line = t.Line
+	token.column = t.Column
+
+	return token
+}
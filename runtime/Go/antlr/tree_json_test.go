@@ -0,0 +1,120 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTreesJSONRoundTrip(t *testing.T) {
+	p, tree := parseCalc(calcEditText)
+	data, err := TreesToJSON(tree, calcRuleNames)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := TreesFromJSON(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := TreesStringTree(decoded, calcRuleNames, nil), tree.ToStringTree(nil, p); got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+
+	// Decoded rule nodes keep their names, so the encoding is the same
+	// without rule names
+	again, err := TreesToJSON(decoded, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(again) != string(data) {
+		t.Errorf("got %s\nwant %s", again, data)
+	}
+
+	// Nodes, tokens and parents are restored
+	want := TreesDescendants(tree)
+	got := TreesDescendants(decoded)
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d nodes, want %d", len(got), len(want))
+	}
+
+	errors := 0
+
+	for i, w := range want {
+		g := got[i]
+
+		if _, ok := w.(ErrorNode); ok {
+			errors++
+
+			if _, ok := g.(ErrorNode); !ok {
+				t.Errorf("node %d: got %T, want an error node", i, g)
+			}
+		}
+
+		if g.GetSourceInterval().String() != w.GetSourceInterval().String() {
+			t.Errorf("node %d: got interval %s, want %s", i, g.GetSourceInterval(), w.GetSourceInterval())
+		}
+
+		if i > 0 && g.GetParent() == nil {
+			t.Errorf("node %d: no parent", i)
+		}
+
+		if wt, ok := w.(TerminalNode); ok {
+			ws, gs := wt.GetSymbol(), g.(TerminalNode).GetSymbol()
+
+			if gs.GetTokenType() != ws.GetTokenType() || gs.GetText() != ws.GetText() || gs.GetLine() != ws.GetLine() || gs.GetColumn() != ws.GetColumn() || gs.GetChannel() != ws.GetChannel() {
+				t.Errorf("node %d: got token %v, want %v", i, gs, ws)
+			}
+		} else {
+			wr, gr := w.(RuleNode).GetRuleContext(), g.(*JSONParserRuleContext)
+
+			if gr.GetRuleIndex() != wr.GetRuleIndex() || gr.GetRuleName() != calcRuleNames[wr.GetRuleIndex()] || gr.GetAltNumber() != wr.GetAltNumber() {
+				t.Errorf("node %d: got rule %d %q alt %d", i, gr.GetRuleIndex(), gr.GetRuleName(), gr.GetAltNumber())
+			}
+		}
+	}
+
+	if errors == 0 {
+		t.Error("no error node in the Calc tree")
+	}
+}
+
+func TestTreesFromJSONErrors(t *testing.T) {
+	for _, data := range []string{
+		``,
+		`{`,
+		`{}`,
+		`{"ruleIndex":0,"token":{"type":1}}`,
+		`{"ruleIndex":0,"children":[{"rule":"expr"}]}`,
+	} {
+		if tree, err := TreesFromJSON([]byte(data)); err == nil {
+			t.Errorf("%q: got tree %v", data, tree)
+		}
+	}
+
+	if _, err := TreesFromJSON([]byte(`{"ruleIndex":0,"children":[{"token":{"type":1,"text":"x"}}]}`)); err != nil {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestTreesToJSONErrorNode(t *testing.T) {
+	_, tree := parseCalc("print (a;")
+	data, err := TreesToJSON(tree, calcRuleNames)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `{"error":true,"token":{"type":7,"text":"\u003cmissing ')'\u003e"`; !strings.Contains(string(data), want) {
+		t.Errorf("%s not in %s", want, data)
+	}
+}
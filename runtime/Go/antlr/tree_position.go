@@ -0,0 +1,191 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import "unicode/utf8"
+
+// TreesFindNodeAtOffset returns the deepest node of t whose tokens cover the
+// char offset, together with its ancestors from the root t down to its parent,
+// in the order of TreesgetAncestors. If the offset falls between two children,
+// on hidden-channel tokens for example, the node returned is their parent. It
+// returns nil and nil if t does not cover the offset.
+func TreesFindNodeAtOffset(t ParseTree, offset int) (ParseTree, []ParseTree) {
+	return findNodeAt(t, treeOffset(offset))
+}
+
+// TreesFindNodeAtPosition is like TreesFindNodeAtOffset but takes a line,
+// counted from 1, and a column, counted in chars from 0, as tokens do.
+func TreesFindNodeAtPosition(t ParseTree, line, column int) (ParseTree, []ParseTree) {
+	return findNodeAt(t, &treeLineColumn{line, column})
+}
+
+// treeTarget is a position in the input of a parse tree.
+type treeTarget interface {
+	// isBefore returns true if the position is before the first char of t.
+	isBefore(t Token) bool
+
+	// isAfter returns true if the position is after the last char of t.
+	isAfter(t Token) bool
+}
+
+type treeOffset int
+
+func (o treeOffset) isBefore(t Token) bool {
+	return int(o) < t.GetStart()
+}
+
+func (o treeOffset) isAfter(t Token) bool {
+	return int(o) > t.GetStop()
+}
+
+type treeLineColumn struct {
+	line   int
+	column int
+}
+
+func (p *treeLineColumn) isBefore(t Token) bool {
+	return p.line < t.GetLine() || (p.line == t.GetLine() && p.column < t.GetColumn())
+}
+
+func (p *treeLineColumn) isAfter(t Token) bool {
+	line, column := t.GetLine(), t.GetColumn()
+
+	// Find the position of the last char of the token
+	text := t.GetText()
+	last := 0
+
+	for i, r := range text {
+		if r == '\n' {
+			line++
+			column = 0
+			last = i + 1
+		}
+	}
+
+	column += utf8.RuneCountInString(text[last:]) - 1
+
+	return p.line > line || (p.line == line && p.column > column)
+}
+
+// findNodeAt descends from t into the child covering target for as long as
+// there is one, finding the child by binary search on the first tokens of the
+// children.
+func findNodeAt(t ParseTree, target treeTarget) (ParseTree, []ParseTree) {
+	if !treeCovers(t, target) {
+		return nil, nil
+	}
+
+	ancestors := make([]ParseTree, 0)
+
+	for {
+		var found ParseTree
+
+		n := t.GetChildCount()
+		lo, hi := 0, n-1
+
+		// Find the last child whose first token does not start after target
+		candidate := -1
+
+		for lo <= hi {
+			mid := (lo + hi) / 2
+			i := mid
+
+			// Children with no tokens in the input, such as empty rules and
+			// tokens conjured up by error recovery, take the place of the
+			// nearest child before them that has some
+			var first Token
+
+			for ; i >= lo; i-- {
+				if first, _ = treeSpan(t.GetChild(i).(ParseTree)); first != nil {
+					break
+				}
+			}
+
+			if first == nil {
+				lo = mid + 1
+			} else if target.isBefore(first) {
+				hi = i - 1
+			} else {
+				candidate = i
+				lo = mid + 1
+			}
+		}
+
+		if candidate >= 0 {
+			if c := t.GetChild(candidate).(ParseTree); treeCovers(c, target) {
+				found = c
+			}
+		}
+
+		if found == nil {
+			return t, ancestors
+		}
+
+		ancestors = append(ancestors, t)
+		t = found
+	}
+}
+
+func treeCovers(t ParseTree, target treeTarget) bool {
+	first, last := treeSpan(t)
+
+	return first != nil && !target.isBefore(first) && !target.isAfter(last)
+}
+
+// treeSpan returns the first and last tokens of t that come from the input,
+// or nil and nil if there are none. The start and stop tokens of a rule are
+// used unless error recovery made them up, in which case the children are
+// searched.
+func treeSpan(t ParseTree) (Token, Token) {
+	switch tt := t.(type) {
+	case TerminalNode:
+		if isInputToken(tt.GetSymbol()) {
+			return tt.GetSymbol(), tt.GetSymbol()
+		}
+
+		return nil, nil
+
+	case ParserRuleContext:
+		interval := tt.GetSourceInterval()
+
+		if interval.start >= 0 && interval.stop >= 0 && interval.stop < interval.start {
+			// An empty rule: the stop token is before the start token
+			return nil, nil
+		}
+
+		first, last := tt.GetStart(), tt.GetStop()
+
+		if !isInputToken(first) {
+			first = nil
+
+			for i := 0; i < t.GetChildCount() && first == nil; i++ {
+				first, _ = treeSpan(t.GetChild(i).(ParseTree))
+			}
+		}
+
+		if !isInputToken(last) {
+			last = nil
+
+			for i := t.GetChildCount() - 1; i >= 0 && last == nil; i-- {
+				_, last = treeSpan(t.GetChild(i).(ParseTree))
+			}
+		}
+
+		if first == nil || last == nil {
+			return nil, nil
+		}
+
+		return first, last
+	}
+
+	return nil, nil
+}
+
+// isInputToken returns true if t covers at least one char of the input.
+// Tokens conjured up by error recovery have no position, and EOF covers no
+// chars.
+func isInputToken(t Token) bool {
+	return t != nil && t.GetTokenIndex() >= 0 && t.GetStart() >= 0 && t.GetStop() >= t.GetStart()
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"strings"
+	"testing"
+)
+
+// foundNodeTexts returns the node text of node and of its ancestors.
+func foundNodeTexts(node ParseTree, ancestors []ParseTree) (string, string) {
+	if node == nil {
+		return "", ""
+	}
+
+	texts := make([]string, 0)
+
+	for _, a := range ancestors {
+		texts = append(texts, TreesGetNodeText(a, calcRuleNames, nil))
+	}
+
+	return TreesGetNodeText(node, calcRuleNames, nil), strings.Join(texts, " ")
+}
+
+func TestTreesFindNodeAtOffset(t *testing.T) {
+	//            0123456789012345
+	text := "x = 1;\nprint x;"
+	_, tree := parseCalc(text)
+
+	tests := []struct {
+		offset    int
+		node      string
+		ancestors string
+	}{
+		{-1, "", ""},
+		{0, "x", "prog stat"},
+		{1, "stat", "prog"},
+		{2, "=", "prog stat"},
+		{4, "1", "prog stat expr atom"},
+		{5, ";", "prog stat"},
+		{6, "prog", ""},
+		{7, "print", "prog stat"},
+		{11, "print", "prog stat"},
+		{12, "stat", "prog"},
+		{13, "x", "prog stat expr atom"},
+		{14, ";", "prog stat"},
+		{15, "", ""},
+	}
+
+	for _, test := range tests {
+		node, ancestors := TreesFindNodeAtOffset(tree, test.offset)
+
+		if n, a := foundNodeTexts(node, ancestors); n != test.node || a != test.ancestors {
+			t.Errorf("offset %d: got %q in %q, want %q in %q", test.offset, n, a, test.node, test.ancestors)
+		}
+	}
+}
+
+func TestTreesFindNodeAtPosition(t *testing.T) {
+	_, tree := parseCalc("x = 1;\nprint \"ab\";")
+
+	tests := []struct {
+		line, column int
+		node         string
+	}{
+		{1, 0, "x"},
+		{1, 5, ";"},
+		{1, 6, "prog"},
+		{2, 0, "print"},
+		{2, 4, "print"},
+		{2, 5, "stat"},
+		{2, 6, "\""},
+		{2, 7, "ab"},
+		{2, 8, "ab"},
+		{2, 9, "\""},
+		{2, 10, ";"},
+		{2, 11, ""},
+		{3, 0, ""},
+	}
+
+	for _, test := range tests {
+		node, ancestors := TreesFindNodeAtPosition(tree, test.line, test.column)
+
+		if n, _ := foundNodeTexts(node, ancestors); n != test.node {
+			t.Errorf("%d:%d: got %q, want %q", test.line, test.column, n, test.node)
+		}
+	}
+}
+
+func TestTreesFindNodeAtOffsetSkipsConjuredTokens(t *testing.T) {
+	// The missing ')' comes before the ';' at offset 8
+	_, tree := parseCalc("print (a;")
+
+	for offset, want := range map[int]string{6: "(", 7: "a", 8: ";"} {
+		node, _ := TreesFindNodeAtOffset(tree, offset)
+
+		if n, _ := foundNodeTexts(node, nil); n != want {
+			t.Errorf("offset %d: got %q, want %q", offset, n, want)
+		}
+	}
+}
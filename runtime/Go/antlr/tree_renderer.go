@@ -0,0 +1,315 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Kinds of nodes drawn by a TreeRenderer.
+const (
+	treeRenderRule = iota
+	treeRenderToken
+	treeRenderError
+	treeRenderMissing
+)
+
+// Sizes in pixels of the SVG drawn by a TreeRenderer.
+const (
+	treeRenderCharWidth   = 7.0
+	treeRenderNodePadding = 16.0
+	treeRenderNodeHeight  = 24.0
+	treeRenderLevelHeight = 56.0
+	treeRenderSiblingGap  = 12.0
+	treeRenderMargin      = 10.0
+)
+
+// TreeRenderer draws parse trees as Graphviz DOT graphs or as standalone SVG
+// images, for documentation or to inspect the effect of grammar changes. Error
+// nodes are drawn in red and tokens conjured up by error recovery, such as
+// <missing ';'>, are drawn dashed in orange.
+type TreeRenderer struct {
+	ruleNames      []string
+	showAltNumbers bool
+}
+
+// NewTreeRenderer returns a TreeRenderer that labels rule nodes using
+// ruleNames, which may be nil.
+func NewTreeRenderer(ruleNames []string) *TreeRenderer {
+	return &TreeRenderer{ruleNames: ruleNames}
+}
+
+// NewTreeRendererForRecognizer returns a TreeRenderer that takes its rule names
+// from recog.
+func NewTreeRendererForRecognizer(recog Recognizer) *TreeRenderer {
+	return NewTreeRenderer(recog.GetRuleNames())
+}
+
+// SetShowAltNumbers sets whether rule nodes are labelled with the alternative
+// number of their context, as in expr:2. Contexts without an alternative
+// number are labelled with the rule name only.
+func (r *TreeRenderer) SetShowAltNumbers(show bool) {
+	r.showAltNumbers = show
+}
+
+func (r *TreeRenderer) IsShowAltNumbers() bool {
+	return r.showAltNumbers
+}
+
+// treeRenderNode is a node of the tree being drawn, with its position in the
+// SVG layout. x is the centre of the node and width the width of its subtree.
+type treeRenderNode struct {
+	label     string
+	kind      int
+	depth     int
+	x         float64
+	width     float64
+	treeWidth float64
+	children  []*treeRenderNode
+}
+
+// GetDOT returns a DOT graph of the tree t with the children of each node in
+// order from left to right.
+func (r *TreeRenderer) GetDOT(t Tree) string {
+	var buf bytes.Buffer
+
+	buf.WriteString("digraph ParseTree {\n")
+	buf.WriteString("ordering=out;\n")
+	buf.WriteString("node [fontname=\"monospace\", fontsize=11];\n")
+
+	id := 0
+	stack := []*treeRenderNode{r.buildNode(t, 0)}
+	ids := []int{id}
+
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		nid := ids[len(ids)-1]
+		stack = stack[:len(stack)-1]
+		ids = ids[:len(ids)-1]
+
+		buf.WriteString("n" + strconv.Itoa(nid) + " [label=" + dotQuote(n.label) + ", " + treeRenderDOTStyle(n.kind) + "];\n")
+
+		childIDs := make([]int, len(n.children))
+
+		for i := range n.children {
+			id++
+			childIDs[i] = id
+			buf.WriteString("n" + strconv.Itoa(nid) + " -> n" + strconv.Itoa(id) + ";\n")
+		}
+
+		// Push in reverse so nodes are written in pre-order
+		for i := len(n.children) - 1; i >= 0; i-- {
+			stack = append(stack, n.children[i])
+			ids = append(ids, childIDs[i])
+		}
+	}
+
+	buf.WriteString("}\n")
+
+	return buf.String()
+}
+
+func treeRenderDOTStyle(kind int) string {
+	switch kind {
+	case treeRenderRule:
+		return "shape=box, style=\"rounded,filled\", fillcolor=\"#eeeeff\", color=\"#9999cc\""
+	case treeRenderError:
+		return "shape=box, style=filled, fillcolor=\"#ffdddd\", color=\"#cc0000\", fontcolor=\"#cc0000\""
+	case treeRenderMissing:
+		return "shape=box, style=\"dashed,filled\", fillcolor=\"#ffeedd\", color=\"#cc6600\", fontcolor=\"#cc6600\""
+	}
+
+	return "shape=plaintext"
+}
+
+// GetSVG returns a standalone SVG image of the tree t. Each subtree gets a band
+// as wide as the wider of its root and its children, with the root centred
+// above the children, so edges never cross.
+func (r *TreeRenderer) GetSVG(t Tree) string {
+	root := r.buildNode(t, 0)
+	depth := r.measure(root)
+
+	r.place(root, treeRenderMargin)
+
+	width := root.treeWidth + 2*treeRenderMargin
+	height := float64(depth)*treeRenderLevelHeight + treeRenderNodeHeight + 2*treeRenderMargin
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%s\" viewBox=\"0 0 %s %s\">\n",
+		svgNumber(width), svgNumber(height), svgNumber(width), svgNumber(height))
+	buf.WriteString("<rect width=\"100%\" height=\"100%\" fill=\"#ffffff\"/>\n")
+
+	// Edges first so the nodes are drawn over them
+	r.writeSVGEdges(&buf, root)
+	r.writeSVGNodes(&buf, root)
+
+	buf.WriteString("</svg>\n")
+
+	return buf.String()
+}
+
+func (r *TreeRenderer) buildNode(t Tree, depth int) *treeRenderNode {
+	n := &treeRenderNode{depth: depth}
+
+	switch tt := t.(type) {
+	case ErrorNode:
+		n.label = treeRenderTokenText(tt.GetSymbol())
+		n.kind = treeRenderError
+
+		if isConjuredNode(tt) {
+			n.kind = treeRenderMissing
+		}
+
+	case TerminalNode:
+		n.label = treeRenderTokenText(tt.GetSymbol())
+		n.kind = treeRenderToken
+
+		if isConjuredNode(tt) {
+			n.kind = treeRenderMissing
+		}
+
+	case RuleNode:
+		n.label = r.ruleLabel(tt.GetRuleContext())
+		n.kind = treeRenderRule
+
+	default:
+		n.label = fmt.Sprint(t.GetPayload())
+	}
+
+	for i := 0; i < t.GetChildCount(); i++ {
+		n.children = append(n.children, r.buildNode(t.GetChild(i), depth+1))
+	}
+
+	return n
+}
+
+func (r *TreeRenderer) ruleLabel(ctx RuleContext) string {
+	ruleIndex := ctx.GetRuleIndex()
+	label := strconv.Itoa(ruleIndex)
+
+	if ruleIndex >= 0 && ruleIndex < len(r.ruleNames) {
+		label = r.ruleNames[ruleIndex]
+	} else if d, ok := ctx.(*JSONParserRuleContext); ok && d.ruleName != "" {
+		label = d.ruleName
+	}
+
+	if r.showAltNumbers && ctx.GetAltNumber() != ATNInvalidAltNumber {
+		label += ":" + strconv.Itoa(ctx.GetAltNumber())
+	}
+
+	return label
+}
+
+func treeRenderTokenText(t Token) string {
+	if t == nil {
+		return ""
+	}
+
+	if t.GetTokenType() == TokenEOF {
+		return "<EOF>"
+	}
+
+	return t.GetText()
+}
+
+// isConjuredNode returns true if the token of n was made up by error recovery
+// rather than read from the input. The tokens created by
+// DefaultErrorStrategy.GetMissingSymbol have no start and stop in the input.
+func isConjuredNode(n TerminalNode) bool {
+	t := n.GetSymbol()
+
+	return t != nil && (t.GetStart() < 0 || t.GetStop() < 0)
+}
+
+// measure computes the widths of n and its subtree and returns the depth of
+// the deepest node below n.
+func (r *TreeRenderer) measure(n *treeRenderNode) int {
+	n.width = float64(utf8.RuneCountInString(n.label))*treeRenderCharWidth + treeRenderNodePadding
+
+	depth := n.depth
+	childrenWidth := 0.0
+
+	for i, c := range n.children {
+		depth = intMax(depth, r.measure(c))
+
+		if i > 0 {
+			childrenWidth += treeRenderSiblingGap
+		}
+
+		childrenWidth += c.treeWidth
+	}
+
+	n.treeWidth = n.width
+
+	if childrenWidth > n.treeWidth {
+		n.treeWidth = childrenWidth
+	}
+
+	return depth
+}
+
+// place positions the subtree of n in the band starting at left.
+func (r *TreeRenderer) place(n *treeRenderNode, left float64) {
+	n.x = left + n.treeWidth/2
+
+	childrenWidth := -treeRenderSiblingGap
+
+	for _, c := range n.children {
+		childrenWidth += c.treeWidth + treeRenderSiblingGap
+	}
+
+	x := left + (n.treeWidth-childrenWidth)/2
+
+	for _, c := range n.children {
+		r.place(c, x)
+		x += c.treeWidth + treeRenderSiblingGap
+	}
+}
+
+func (r *TreeRenderer) writeSVGEdges(buf *bytes.Buffer, n *treeRenderNode) {
+	for _, c := range n.children {
+		fmt.Fprintf(buf, "<line x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\" stroke=\"#888888\"/>\n",
+			svgNumber(n.x), svgNumber(treeRenderTop(n)+treeRenderNodeHeight), svgNumber(c.x), svgNumber(treeRenderTop(c)))
+
+		r.writeSVGEdges(buf, c)
+	}
+}
+
+func (r *TreeRenderer) writeSVGNodes(buf *bytes.Buffer, n *treeRenderNode) {
+	fill, stroke, text, dash := "#ffffff", "#ffffff", "#000000", ""
+
+	switch n.kind {
+	case treeRenderRule:
+		fill, stroke = "#eeeeff", "#9999cc"
+	case treeRenderError:
+		fill, stroke, text = "#ffdddd", "#cc0000", "#cc0000"
+	case treeRenderMissing:
+		fill, stroke, text, dash = "#ffeedd", "#cc6600", "#cc6600", " stroke-dasharray=\"4,2\""
+	}
+
+	top := treeRenderTop(n)
+
+	fmt.Fprintf(buf, "<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" rx=\"4\" fill=\"%s\" stroke=\"%s\"%s/>\n",
+		svgNumber(n.x-n.width/2), svgNumber(top), svgNumber(n.width), svgNumber(treeRenderNodeHeight), fill, stroke, dash)
+	fmt.Fprintf(buf, "<text x=\"%s\" y=\"%s\" text-anchor=\"middle\" font-family=\"monospace\" font-size=\"12\" fill=\"%s\">%s</text>\n",
+		svgNumber(n.x), svgNumber(top+treeRenderNodeHeight/2+4), text, html.EscapeString(n.label))
+
+	for _, c := range n.children {
+		r.writeSVGNodes(buf, c)
+	}
+}
+
+func treeRenderTop(n *treeRenderNode) float64 {
+	return treeRenderMargin + float64(n.depth)*treeRenderLevelHeight
+}
+
+func svgNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	treeRenderTestToken   = `shape=plaintext];`
+	treeRenderTestError   = `shape=box, style=filled, fillcolor="#ffdddd", color="#cc0000", fontcolor="#cc0000"];`
+	treeRenderTestMissing = `shape=box, style="dashed,filled", fillcolor="#ffeedd", color="#cc6600", fontcolor="#cc6600"];`
+)
+
+func TestTreeRendererNodeKinds(t *testing.T) {
+	// A missing ')', an extraneous ';' and a string that reads like a
+	// missing token
+	_, tree := parseCalc("print (a; print (a ;) \"<missing>\" : x;")
+	r := NewTreeRenderer(calcRuleNames)
+	dot := r.GetDOT(tree)
+
+	for _, want := range []string{
+		`[label="<missing ')'>", ` + treeRenderTestMissing,
+		`[label=";", ` + treeRenderTestError,
+		`[label="<missing>", ` + treeRenderTestToken,
+		`[label="prog", shape=box, style="rounded,filled", fillcolor="#eeeeff", color="#9999cc"];`,
+		`[label="<EOF>", ` + treeRenderTestToken,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("%s not in\n%s", want, dot)
+		}
+	}
+
+	if n := strings.Count(dot, treeRenderTestMissing); n != 1 {
+		t.Errorf("got %d missing tokens, want 1", n)
+	}
+
+	if n := strings.Count(dot, treeRenderTestError); n != 1 {
+		t.Errorf("got %d error tokens, want 1", n)
+	}
+
+	svg := r.GetSVG(tree)
+
+	if n := strings.Count(svg, "stroke-dasharray"); n != 1 {
+		t.Errorf("got %d dashed nodes in the SVG, want 1", n)
+	}
+
+	if !strings.Contains(svg, ">&lt;missing &#39;)&#39;&gt;</text>") {
+		t.Errorf("missing token label not escaped in\n%s", svg)
+	}
+}
+
+func TestTreeRendererUnbufferedTokens(t *testing.T) {
+	// Tokens read without a buffer have no token index, but they were in the
+	// input
+	prc := NewBaseParserRuleContext(nil, CalcParserRULE_atom)
+	prc.AddTokenNode(NewCommonToken(&TokenSourceCharStreamPair{}, CalcParserID, TokenDefaultChannel, 0, 0))
+	prc.AddErrorNode(NewCommonToken(&TokenSourceCharStreamPair{}, CalcParserSEMI, TokenDefaultChannel, 1, 1))
+
+	for _, c := range prc.GetChildren() {
+		c.(TerminalNode).GetSymbol().SetText("t")
+	}
+
+	dot := NewTreeRenderer(calcRuleNames).GetDOT(prc)
+
+	if strings.Contains(dot, treeRenderTestMissing) {
+		t.Errorf("tokens without an index drawn as missing:\n%s", dot)
+	}
+
+	if !strings.Contains(dot, `[label="t", `+treeRenderTestToken) || !strings.Contains(dot, `[label="t", `+treeRenderTestError) {
+		t.Errorf("got\n%s", dot)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"strings"
+	"testing"
+)
+
+// calcWalkControl is a calcEventRecorder that asks the walker to skip the
+// children of a rule or to stop at given events.
+type calcWalkControl struct {
+	*calcEventRecorder
+	*BaseWalkController
+}
+
+func newCalcWalkControl(skip, stop string) *calcWalkControl {
+	c := &calcWalkControl{
+		calcEventRecorder:  &calcEventRecorder{BaseCalcListener: &BaseCalcListener{}},
+		BaseWalkController: &BaseWalkController{},
+	}
+
+	c.onEvent = func(event string) {
+		if event == skip {
+			c.SkipChildren()
+		}
+
+		if event == stop {
+			c.StopWalk()
+		}
+	}
+
+	return c
+}
+
+func walkCalcEventsIteratively(t Tree) string {
+	r := &calcEventRecorder{BaseCalcListener: &BaseCalcListener{}}
+
+	NewIterativeParseTreeWalker().Walk(r, t)
+
+	return strings.Join(r.events, "\n")
+}
+
+func TestIterativeParseTreeWalkerMatchesParseTreeWalker(t *testing.T) {
+	inputs := []string{
+		calcEditText,
+		"",
+		"x = ;  y = 3;",
+		"print 1,,2 3;",
+		"{ { \"a\" \"b\" : ( ) } ",
+	}
+
+	for _, input := range inputs {
+		_, tree := parseCalc(input)
+		nodes := TreesDescendants(tree)
+
+		if len(nodes) == 0 {
+			t.Fatalf("%q: no nodes", input)
+		}
+
+		// Walk from every node, including terminal and error nodes
+		for _, node := range nodes {
+			want := walkCalcEvents(node)
+
+			if got := walkCalcEventsIteratively(node); got != want {
+				t.Errorf("%q from %q:\ngot:\n%s\nwant:\n%s", input, node.GetText(), got, want)
+			}
+		}
+	}
+}
+
+func TestIterativeParseTreeWalkerWalksErrorNodes(t *testing.T) {
+	_, tree := parseCalc(calcEditText)
+	events := walkCalcEventsIteratively(tree)
+
+	if !strings.Contains(events, "terminal 1\nexit atom\np 1\nexit expr\nerror )\nterminal ;") {
+		t.Errorf("error node not walked in place:\n%s", events)
+	}
+}
+
+func TestIterativeParseTreeWalkerControl(t *testing.T) {
+	tests := []struct {
+		input      string
+		skip, stop string
+		want       string
+	}{
+		// Skipping children still exits the rule
+		{"x = a + 1;", "enter expr", "", "enter prog\nenter stat\nterminal x\nterminal =\nenter expr\np 1\nexit expr\nterminal ;\nvalue a+1\nexit stat\nterminal <EOF>\nexit prog"},
+		{"\"s\" : y;", "enter str", "", "enter prog\nenter stat\nenter str\nexit str\nterminal :\nenter stat\nenter expr\nenter atom\nterminal y\nexit atom\np 0\nexit expr\nterminal ;\nexit stat\nexit stat\nterminal <EOF>\nexit prog"},
+		// Skipping only applies when entering a rule
+		{"y;", "terminal y", "", "enter prog\nenter stat\nenter expr\nenter atom\nterminal y\nexit atom\np 0\nexit expr\nterminal ;\nexit stat\nterminal <EOF>\nexit prog"},
+		{"y;", "exit atom", "", "enter prog\nenter stat\nenter expr\nenter atom\nterminal y\nexit atom\np 0\nexit expr\nterminal ;\nexit stat\nterminal <EOF>\nexit prog"},
+		// Stopping sends no more events
+		{"print a, b;", "", "terminal print", "enter prog\nenter stat\nterminal print"},
+		{"print a, b;", "", "enter atom", "enter prog\nenter stat\nterminal print\nenter expr\nenter atom"},
+		{"print a, b;", "", "exit atom", "enter prog\nenter stat\nterminal print\nenter expr\nenter atom\nterminal a\nexit atom"},
+		{"x = 1 ) ;", "", "error )", "enter prog\nenter stat\nterminal x\nterminal =\nenter expr\nenter atom\nterminal 1\nexit atom\np 1\nexit expr\nerror )"},
+		{"y;", "", "exit prog", "enter prog\nenter stat\nenter expr\nenter atom\nterminal y\nexit atom\np 0\nexit expr\nterminal ;\nexit stat\nterminal <EOF>\nexit prog"},
+		// Stopping wins over skipping
+		{"y;", "enter expr", "enter expr", "enter prog\nenter stat\nenter expr"},
+	}
+
+	for _, test := range tests {
+		_, tree := parseCalc(test.input)
+		c := newCalcWalkControl(test.skip, test.stop)
+
+		NewIterativeParseTreeWalker().Walk(c, tree)
+
+		if got := strings.Join(c.events, "\n"); got != test.want {
+			t.Errorf("%q skipping at %q, stopping at %q:\ngot:\n%s\nwant:\n%s", test.input, test.skip, test.stop, got, test.want)
+		}
+	}
+}
+
+func TestIterativeParseTreeWalkerForgetsEarlierRequests(t *testing.T) {
+	_, tree := parseCalc("y;")
+	c := newCalcWalkControl("", "")
+
+	c.StopWalk()
+	NewIterativeParseTreeWalker().Walk(c, tree)
+
+	if got, want := strings.Join(c.events, "\n"), walkCalcEvents(tree); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestIterativeParseTreeWalkerDeepTree(t *testing.T) {
+	const depth = 100000
+
+	token := func(tokenType int, text string) Token {
+		t := NewCommonToken(&TokenSourceCharStreamPair{}, tokenType, TokenDefaultChannel, 0, 0)
+		t.SetText(text)
+
+		return t
+	}
+
+	// expr : atom ; atom : '(' expr ')' nested depth times
+	root := NewExprContext(nil, nil, -1, 0)
+	ctx := root
+
+	for i := 1; i < depth; i++ {
+		atom := NewAtomContext(nil, ctx, -1)
+		expr := NewExprContext(nil, atom, -1, i)
+
+		ctx.AddChild(atom)
+		atom.AddTokenNode(token(CalcParserLPAREN, "("))
+		atom.AddChild(expr)
+		atom.AddTokenNode(token(CalcParserRPAREN, ")"))
+
+		ctx = expr
+	}
+
+	ctx.AddErrorNode(token(CalcParserSEMI, ";"))
+
+	want := walkCalcEvents(root)
+	got := walkCalcEventsIteratively(root)
+
+	if got != want {
+		t.Fatal("iterative walk of deep tree differs")
+	}
+
+	if n := strings.Count(got, "\n") + 1; n != 7*(depth-1)+4 {
+		t.Errorf("got %d events, want %d", n, 7*(depth-1)+4)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"strconv"
+	"sync"
+)
+
+// Vocabulary maps the token types of a grammar to their names and back.
+type Vocabulary interface {
+	// GetMaxTokenType returns the highest token type that has a name.
+	GetMaxTokenType() int
+
+	// GetLiteralName returns the literal of ttype as written in the grammar,
+	// such as "'+'", or "" if ttype is not defined by a literal.
+	GetLiteralName(ttype int) string
+
+	// GetSymbolicName returns the name of ttype as written in the grammar,
+	// such as "PLUS", or "" if ttype has none. The symbolic name of TokenEOF
+	// is "EOF".
+	GetSymbolicName(ttype int) string
+
+	// GetDisplayName returns the name of ttype to show in messages: its
+	// display name, literal name or symbolic name, whichever is found first,
+	// or else the number itself.
+	GetDisplayName(ttype int) string
+
+	// GetTokenType returns the type of the token with the given literal or
+	// symbolic name, or TokenInvalidType if there is none.
+	GetTokenType(name string) int
+
+	// GetTokenTypeMap returns a map from the literal and symbolic names of
+	// the tokens to their types. The map is shared and must not be modified.
+	GetTokenTypeMap() map[string]int
+}
+
+type VocabularyImpl struct {
+	literalNames  []string
+	symbolicNames []string
+	displayNames  []string
+	maxTokenType  int
+
+	tokenTypeMapOnce sync.Once
+	tokenTypeMap     map[string]int
+}
+
+// VocabularyEmpty is a Vocabulary with no token names.
+var VocabularyEmpty = NewVocabularyImpl(nil, nil, nil)
+
+// NewVocabularyImpl returns a Vocabulary for the given names, indexed by token
+// type. Any of the slices may be nil, and empty strings mean there is no name.
+func NewVocabularyImpl(literalNames, symbolicNames, displayNames []string) *VocabularyImpl {
+	v := &VocabularyImpl{
+		literalNames:  literalNames,
+		symbolicNames: symbolicNames,
+		displayNames:  displayNames,
+	}
+
+	v.maxTokenType = intMax(len(displayNames), intMax(len(literalNames), len(symbolicNames))) - 1
+
+	return v
+}
+
+func (v *VocabularyImpl) GetMaxTokenType() int {
+	return v.maxTokenType
+}
+
+func (v *VocabularyImpl) GetLiteralName(ttype int) string {
+	if ttype >= 0 && ttype < len(v.literalNames) {
+		return v.literalNames[ttype]
+	}
+
+	return ""
+}
+
+func (v *VocabularyImpl) GetSymbolicName(ttype int) string {
+	if ttype >= 0 && ttype < len(v.symbolicNames) {
+		return v.symbolicNames[ttype]
+	}
+
+	if ttype == TokenEOF {
+		return "EOF"
+	}
+
+	return ""
+}
+
+func (v *VocabularyImpl) GetDisplayName(ttype int) string {
+	if ttype >= 0 && ttype < len(v.displayNames) && v.displayNames[ttype] != "" {
+		return v.displayNames[ttype]
+	}
+
+	if name := v.GetLiteralName(ttype); name != "" {
+		return name
+	}
+
+	if name := v.GetSymbolicName(ttype); name != "" {
+		return name
+	}
+
+	return strconv.Itoa(ttype)
+}
+
+func (v *VocabularyImpl) GetTokenType(name string) int {
+	if ttype, ok := v.GetTokenTypeMap()[name]; ok {
+		return ttype
+	}
+
+	return TokenInvalidType
+}
+
+func (v *VocabularyImpl) GetTokenTypeMap() map[string]int {
+	v.tokenTypeMapOnce.Do(func() {
+		m := make(map[string]int)
+
+		for ttype := 0; ttype <= v.maxTokenType; ttype++ {
+			if name := v.GetLiteralName(ttype); name != "" {
+				m[name] = ttype
+			}
+
+			if name := v.GetSymbolicName(ttype); name != "" {
+				m[name] = ttype
+			}
+		}
+
+		m["EOF"] = TokenEOF
+
+		v.tokenTypeMap = m
+	})
+
+	return v.tokenTypeMap
+}
+
+// VocabularyRecognizer is implemented by recognizers that look up their token
+// and rule names, as BaseRecognizer does. It is not part of Recognizer so that
+// recognizers written against Recognizer need not implement it.
+type VocabularyRecognizer interface {
+	Recognizer
+
+	GetVocabulary() Vocabulary
+	GetTokenTypeMap() map[string]int
+	GetRuleIndexMap() map[string]int
+	GetTokenType(tokenName string) int
+}
+
+// getRecognizerVocabulary returns the Vocabulary of recog, or one made from its
+// literal and symbolic names if it is not a VocabularyRecognizer.
+func getRecognizerVocabulary(recog Recognizer) Vocabulary {
+	if v, ok := recog.(VocabularyRecognizer); ok {
+		return v.GetVocabulary()
+	}
+
+	return NewVocabularyImpl(recog.GetLiteralNames(), recog.GetSymbolicNames(), nil)
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2012-2016 The ANTLR Project. All rights reserved.
+// Use of this file is governed by the BSD 3-clause license that
+// can be found in the LICENSE.txt file in the project root.
+
+package antlr
+
+import (
+	"testing"
+)
+
+func TestVocabularyNames(t *testing.T) {
+	v := NewVocabularyImpl(calcLiteralNames, calcSymbolicNames, []string{"", "", "", "semicolon"})
+
+	if got := v.GetMaxTokenType(); got != CalcLexerSTRING_END {
+		t.Errorf("got max token type %d, want %d", got, CalcLexerSTRING_END)
+	}
+
+	tests := []struct {
+		ttype                      int
+		literal, symbolic, display string
+	}{
+		{CalcLexerPLUS, "'+'", "PLUS", "'+'"},
+		{CalcLexerSEMI, "';'", "SEMI", "semicolon"},
+		{CalcLexerID, "", "ID", "ID"},
+		{TokenEOF, "", "EOF", "EOF"},
+		{100, "", "", "100"},
+	}
+
+	for _, test := range tests {
+		if got := v.GetLiteralName(test.ttype); got != test.literal {
+			t.Errorf("%d: got literal name %q, want %q", test.ttype, got, test.literal)
+		}
+
+		if got := v.GetSymbolicName(test.ttype); got != test.symbolic {
+			t.Errorf("%d: got symbolic name %q, want %q", test.ttype, got, test.symbolic)
+		}
+
+		if got := v.GetDisplayName(test.ttype); got != test.display {
+			t.Errorf("%d: got display name %q, want %q", test.ttype, got, test.display)
+		}
+	}
+
+	for name, want := range map[string]int{"'+'": CalcLexerPLUS, "PLUS": CalcLexerPLUS, "EOF": TokenEOF, "semicolon": TokenInvalidType, "": TokenInvalidType} {
+		if got := v.GetTokenType(name); got != want {
+			t.Errorf("%q: got token type %d, want %d", name, got, want)
+		}
+	}
+
+	if got := VocabularyEmpty.GetDisplayName(CalcLexerPLUS); got != "5" {
+		t.Errorf("got display name %q from the empty vocabulary", got)
+	}
+}
+
+func TestRecognizerNameLookups(t *testing.T) {
+	p := NewCalcParser(nil)
+
+	if got := p.GetTokenType("'{'"); got != CalcParserLBRACE {
+		t.Errorf("got token type %d, want %d", got, CalcParserLBRACE)
+	}
+
+	if got := p.GetRuleIndex("block"); got != CalcParserRULE_block {
+		t.Errorf("got rule index %d, want %d", got, CalcParserRULE_block)
+	}
+
+	if got := p.GetRuleIndex("missing"); got != -1 {
+		t.Errorf("got rule index %d for an unknown rule", got)
+	}
+
+	// The literal and symbolic names other than the empty first ones, and EOF
+	if len(p.GetRuleIndexMap()) != len(calcRuleNames) || len(p.GetTokenTypeMap()) != len(calcLiteralNames)+len(calcSymbolicNames)-1 {
+		t.Errorf("got %d rules and %d token names", len(p.GetRuleIndexMap()), len(p.GetTokenTypeMap()))
+	}
+
+	if p.GetVocabulary() != p.GetVocabulary() {
+		t.Error("vocabulary built twice")
+	}
+
+	if got := NewCalcLexer(nil).GetTokenType("WS"); got != CalcLexerWS {
+		t.Errorf("got lexer token type %d, want %d", got, CalcLexerWS)
+	}
+}
+
+func TestRecognizerNameLookupsPerInstance(t *testing.T) {
+	// Recognizers whose names share a backing array do not share lookups
+	names := []string{"a", "b"}
+	r1 := NewBaseRecognizer()
+	r1.RuleNames = names
+	r1.LiteralNames = names
+
+	if r1.GetRuleIndexMap()["a"] != 0 || r1.GetTokenType("a") != 0 {
+		t.Fatal("a not found")
+	}
+
+	names[0] = "c"
+
+	r2 := NewBaseRecognizer()
+	r2.RuleNames = names
+	r2.LiteralNames = names
+
+	if _, ok := r2.GetRuleIndexMap()["c"]; !ok {
+		t.Errorf("got rule index map %v", r2.GetRuleIndexMap())
+	}
+
+	if got := r2.GetTokenType("c"); got != 0 {
+		t.Errorf("got token type %d for c", got)
+	}
+}
+
+func TestGetRecognizerVocabulary(t *testing.T) {
+	p := NewCalcParser(nil)
+
+	if got := getRecognizerVocabulary(p); got != p.GetVocabulary() {
+		t.Error("vocabulary of a VocabularyRecognizer not used")
+	}
+
+	// A Recognizer without vocabulary methods
+	r := struct{ Recognizer }{p}
+
+	if _, ok := interface{}(r).(VocabularyRecognizer); ok {
+		t.Fatal("wrapper is a VocabularyRecognizer")
+	}
+
+	if got := getRecognizerVocabulary(r).GetDisplayName(CalcParserRPAREN); got != "')'" {
+		t.Errorf("got display name %q", got)
+	}
+}